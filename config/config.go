@@ -0,0 +1,250 @@
+// Package config loads router routes from JSON or YAML file.
+//
+// Routes file is a list of routes with kind of the handler:
+//
+//	routes:
+//	  - pattern: /old
+//	    kind: redirect
+//	    target: /new
+//	  - pattern: /static/
+//	    kind: static
+//	    target: ./public
+//	  - pattern: /api/
+//	    kind: proxy
+//	    target: http://127.0.0.1:8080
+//	  - pattern: /maintenance
+//	    kind: status
+//	    code: 503
+//	    body: Service is under maintenance
+//	  - pattern: /users/{id}
+//	    method: GET
+//	    kind: handler-ref
+//	    target: users.show
+//
+// Handlers for the handler-ref routes are defined in the Registry:
+//
+//	loader := config.NewLoader(r, "routes.yaml", config.Registry{
+//	    "users.show": http.HandlerFunc(showUser),
+//	})
+//	if err := loader.Load(); err != nil {
+//	    log.Fatal(err)
+//	}
+//	go loader.Watch(ctx, 5*time.Second)
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cesbo/go-router"
+	"gopkg.in/yaml.v3"
+)
+
+// Route kinds.
+const (
+	// KindRedirect redirects to the target URL with code, 301 by default.
+	KindRedirect = "redirect"
+	// KindStatic serves files from the target directory.
+	// If pattern is not a directory, target is a file.
+	KindStatic = "static"
+	// KindProxy passes requests to the target URL.
+	// Request path is appended to the target path.
+	KindProxy = "proxy"
+	// KindStatus replies with code and body.
+	// If body is not defined, status text is used.
+	KindStatus = "status"
+	// KindHandlerRef serves requests with the target handler from the Registry.
+	KindHandlerRef = "handler-ref"
+)
+
+// Registry is a set of named handlers for the handler-ref routes.
+type Registry map[string]http.Handler
+
+// Route is a route definition.
+type Route struct {
+	// Pattern is a route pattern same as for Router.Handle.
+	Pattern string
+	// Method is a request method. Empty method serves any method.
+	Method string
+	// Kind is a kind of the handler.
+	Kind string
+	// Target is a redirect location, static directory,
+	// proxy URL or handler name in the Registry.
+	Target string
+	// Code is a status code for redirect and status routes.
+	Code int
+	// Body is a reply body for the status route.
+	Body string
+	// Line is a line number of the route in the file.
+	Line int
+}
+
+// Config is a list of routes.
+type Config struct {
+	Routes []*Route
+}
+
+// Error is a configuration error.
+type Error struct {
+	// File is a path to the routes file. Empty if config is parsed from data.
+	File string
+	Line int
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	if e.File != "" {
+		return fmt.Sprintf("config: %s:%d: %s", e.File, e.Line, e.Msg)
+	}
+	return fmt.Sprintf("config: line %d: %s", e.Line, e.Msg)
+}
+
+func errorf(node *yaml.Node, format string, args ...any) error {
+	return &Error{
+		Line: node.Line,
+		Msg:  fmt.Sprintf(format, args...),
+	}
+}
+
+// Parse parses routes from JSON or YAML data.
+// Returns all errors found in the data joined with errors.Join.
+func Parse(data []byte) (*Config, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+
+	c := &Config{}
+	if len(doc.Content) == 0 {
+		// empty file
+		return c, nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, errorf(root, "expected object with routes")
+	}
+
+	var errs []error
+
+	for i := 0; i < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		if key.Value != "routes" {
+			errs = append(errs, errorf(key, "unknown field %q", key.Value))
+			continue
+		}
+
+		if value.Kind != yaml.SequenceNode {
+			errs = append(errs, errorf(value, "routes should be a list"))
+			continue
+		}
+
+		for _, node := range value.Content {
+			route, err := parseRoute(node)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			c.Routes = append(c.Routes, route)
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// parseRoute parses and validates the route definition.
+func parseRoute(node *yaml.Node) (*Route, error) {
+	if node.Kind != yaml.MappingNode {
+		return nil, errorf(node, "route should be an object")
+	}
+
+	route := &Route{
+		Line: node.Line,
+	}
+
+	var errs []error
+
+	for i := 0; i < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+
+		if value.Kind != yaml.ScalarNode {
+			errs = append(errs, errorf(value, "%s should be a scalar", key.Value))
+			continue
+		}
+
+		switch key.Value {
+		case "pattern":
+			route.Pattern = value.Value
+		case "method":
+			route.Method = strings.ToUpper(value.Value)
+		case "kind":
+			route.Kind = value.Value
+		case "target":
+			route.Target = value.Value
+		case "body":
+			route.Body = value.Value
+		case "code":
+			code, err := strconv.Atoi(value.Value)
+			if err != nil || code < 100 || code > 599 {
+				errs = append(errs, errorf(value, "invalid status code %q", value.Value))
+				continue
+			}
+			route.Code = code
+		default:
+			errs = append(errs, errorf(key, "unknown field %q", key.Value))
+		}
+	}
+
+	if route.Pattern == "" {
+		errs = append(errs, errorf(node, "pattern is not defined"))
+	}
+
+	if err := route.validate(); err != nil {
+		errs = append(errs, errorf(node, "%s", err))
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return route, nil
+}
+
+// Apply registers routes in the transaction.
+// Route conflicts are returned as errors with the route line.
+func (c *Config) Apply(tx *router.Tx, registry Registry) error {
+	for _, route := range c.Routes {
+		if err := route.apply(tx, registry); err != nil {
+			return &Error{
+				Line: route.Line,
+				Msg:  err.Error(),
+			}
+		}
+	}
+
+	return nil
+}
+
+// apply registers the route in the transaction.
+func (r *Route) apply(tx *router.Tx, registry Registry) (err error) {
+	handler, err := r.handler(registry)
+	if err != nil {
+		return err
+	}
+
+	// router panics on invalid pattern
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("%v", p)
+		}
+	}()
+
+	return tx.TryHandleMethod(r.Method, r.Pattern, handler)
+}
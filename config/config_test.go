@@ -0,0 +1,197 @@
+package config
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cesbo/go-router"
+	"github.com/stretchr/testify/assert"
+)
+
+func serve(r *router.Router, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+func TestParse(t *testing.T) {
+	assert := assert.New(t)
+
+	c, err := Parse([]byte(`
+routes:
+  - pattern: /old
+    kind: redirect
+    target: /new
+  - pattern: /users/{id}
+    method: get
+    kind: handler-ref
+    target: users.show
+`))
+	if !assert.NoError(err) {
+		return
+	}
+
+	assert.Equal([]*Route{
+		{Pattern: "/old", Kind: KindRedirect, Target: "/new", Line: 3},
+		{Pattern: "/users/{id}", Method: http.MethodGet, Kind: KindHandlerRef, Target: "users.show", Line: 6},
+	}, c.Routes)
+}
+
+func TestParse_json(t *testing.T) {
+	assert := assert.New(t)
+
+	c, err := Parse([]byte(`{
+  "routes": [
+    {"pattern": "/maintenance", "kind": "status", "code": 503}
+  ]
+}`))
+	if assert.NoError(err) {
+		assert.Equal([]*Route{
+			{Pattern: "/maintenance", Kind: KindStatus, Code: 503, Line: 3},
+		}, c.Routes)
+	}
+}
+
+func TestParse_errors(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := Parse([]byte(`
+routes:
+  - pattern: /a
+    kind: unknown
+  - pattern: /b
+    kind: status
+    code: 1000
+  - kind: redirect
+    target: /c
+  - pattern: /d
+    kind: proxy
+    target: /d
+    timeout: 10
+`))
+	assert.EqualError(err, `config: line 3: unknown kind "unknown"
+config: line 7: invalid status code "1000"
+config: line 5: status code is not defined
+config: line 8: pattern is not defined
+config: line 13: unknown field "timeout"
+config: line 10: invalid proxy target "/d"`)
+
+	var e *Error
+	if assert.True(errors.As(err, &e)) {
+		assert.Equal(3, e.Line)
+	}
+
+	_, err = Parse([]byte("routes: [\n"))
+	assert.Error(err)
+}
+
+func TestConfig_Apply(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	assert.NoError(os.WriteFile(filepath.Join(dir, "index.txt"), []byte("static"), 0o644))
+
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "proxy "+r.URL.Path)
+	}))
+	defer backend.Close()
+
+	c := &Config{
+		Routes: []*Route{
+			{Pattern: "/old", Kind: KindRedirect, Target: "/new"},
+			{Pattern: "/static/", Kind: KindStatic, Target: dir},
+			{Pattern: "/file", Kind: KindStatic, Target: filepath.Join(dir, "index.txt")},
+			{Pattern: "/api/", Kind: KindProxy, Target: backend.URL},
+			{Pattern: "/maintenance", Kind: KindStatus, Code: http.StatusServiceUnavailable},
+			{Pattern: "/users", Method: http.MethodPost, Kind: KindHandlerRef, Target: "users"},
+		},
+	}
+
+	r := router.NewRouter()
+	err := r.Update(func(tx *router.Tx) error {
+		return c.Apply(tx, Registry{
+			"users": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusCreated)
+			}),
+		})
+	})
+	if !assert.NoError(err) {
+		return
+	}
+
+	w := serve(r, http.MethodGet, "/old")
+	assert.Equal(http.StatusMovedPermanently, w.Code)
+	assert.Equal("/new", w.Header().Get("Location"))
+
+	w = serve(r, http.MethodGet, "/static/index.txt")
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Equal("static", w.Body.String())
+
+	w = serve(r, http.MethodGet, "/file")
+	assert.Equal("static", w.Body.String())
+
+	w = serve(r, http.MethodGet, "/api/users")
+	assert.Equal("proxy /api/users", w.Body.String())
+
+	w = serve(r, http.MethodGet, "/maintenance")
+	assert.Equal(http.StatusServiceUnavailable, w.Code)
+	assert.Equal("Service Unavailable", w.Body.String())
+
+	w = serve(r, http.MethodPost, "/users")
+	assert.Equal(http.StatusCreated, w.Code)
+
+	// static handler outside of the router
+	h, err := c.Routes[1].handler(nil)
+	if assert.NoError(err) {
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/static/index.txt", nil))
+		assert.Equal(http.StatusNotFound, w.Code)
+	}
+}
+
+func TestConfig_Apply_errors(t *testing.T) {
+	assert := assert.New(t)
+	r := router.NewRouter()
+
+	apply := func(c *Config) error {
+		return r.Update(func(tx *router.Tx) error {
+			return c.Apply(tx, Registry{})
+		})
+	}
+
+	err := apply(&Config{
+		Routes: []*Route{
+			{Pattern: "/a", Kind: KindHandlerRef, Target: "unknown", Line: 2},
+		},
+	})
+	assert.EqualError(err, `config: line 2: handler "unknown" is not registered`)
+
+	err = apply(&Config{
+		Routes: []*Route{
+			{Pattern: "/a/{", Kind: KindStatus, Code: 204, Line: 3},
+		},
+	})
+	if assert.Error(err) {
+		assert.True(strings.HasPrefix(err.Error(), "config: line 3: router: invalid pattern"))
+	}
+
+	err = apply(&Config{
+		Routes: []*Route{
+			{Pattern: "/a", Kind: KindStatus, Code: 204, Line: 4},
+			{Pattern: "/a", Kind: KindStatus, Code: 205, Line: 5},
+		},
+	})
+	var e *Error
+	if assert.True(errors.As(err, &e)) {
+		assert.Equal(5, e.Line)
+	}
+
+	// router is not changed
+	assert.Nil(r.Lookup("/a"))
+}
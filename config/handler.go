@@ -0,0 +1,135 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+
+	"github.com/cesbo/go-router"
+)
+
+// validate checks route fields for the kind.
+func (r *Route) validate() error {
+	switch r.Kind {
+	case KindRedirect:
+		if r.Target == "" {
+			return errors.New("redirect target is not defined")
+		}
+		if r.Code != 0 && (r.Code < 300 || r.Code > 399) {
+			return fmt.Errorf("invalid redirect code %d", r.Code)
+		}
+
+	case KindStatic:
+		if r.Target == "" {
+			return errors.New("static target is not defined")
+		}
+
+	case KindProxy:
+		u, err := url.Parse(r.Target)
+		if err != nil {
+			return fmt.Errorf("invalid proxy target: %w", err)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid proxy target %q", r.Target)
+		}
+
+	case KindStatus:
+		if r.Code == 0 {
+			return errors.New("status code is not defined")
+		}
+
+	case KindHandlerRef:
+		if r.Target == "" {
+			return errors.New("handler name is not defined")
+		}
+
+	case "":
+		return errors.New("kind is not defined")
+
+	default:
+		return fmt.Errorf("unknown kind %q", r.Kind)
+	}
+
+	return nil
+}
+
+// handler makes handler for the route.
+func (r *Route) handler(registry Registry) (http.Handler, error) {
+	switch r.Kind {
+	case KindRedirect:
+		code := r.Code
+		if code == 0 {
+			code = http.StatusMovedPermanently
+		}
+		return http.RedirectHandler(r.Target, code), nil
+
+	case KindStatic:
+		if strings.HasSuffix(r.Pattern, "/") {
+			return newStatic(r.Target), nil
+		}
+
+		file := r.Target
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			http.ServeFile(w, req, file)
+		}), nil
+
+	case KindProxy:
+		u, err := url.Parse(r.Target)
+		if err != nil {
+			return nil, err
+		}
+		return httputil.NewSingleHostReverseProxy(u), nil
+
+	case KindStatus:
+		return newStatus(r.Code, r.Body), nil
+
+	case KindHandlerRef:
+		handler, ok := registry[r.Target]
+		if !ok {
+			return nil, fmt.Errorf("handler %q is not registered", r.Target)
+		}
+		return handler, nil
+	}
+
+	return nil, fmt.Errorf("unknown kind %q", r.Kind)
+}
+
+// newStatic returns handler to serve files from the directory.
+// File path is a part of the request path after the route pattern.
+func newStatic(dir string) http.Handler {
+	fs := http.FileServer(http.Dir(dir))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		match, ok := router.MatchFrom(r)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		r2 := new(http.Request)
+		*r2 = *r
+		r2.URL = new(url.URL)
+		*r2.URL = *r.URL
+		r2.URL.Path = "/" + match.Tail
+		r2.URL.RawPath = ""
+
+		fs.ServeHTTP(w, r2)
+	})
+}
+
+// newStatus returns handler to reply with the status code and body.
+func newStatus(code int, body string) http.Handler {
+	if body == "" {
+		body = http.StatusText(code)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(code)
+		w.Write([]byte(body))
+	})
+}
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/cesbo/go-router"
+)
+
+// Loader loads routes from the file to the router.
+type Loader struct {
+	router   *router.Router
+	path     string
+	registry Registry
+
+	// ErrorLog is a logger for reload errors in Watch.
+	// If nil, errors are logged with the log package's standard logger.
+	ErrorLog *log.Logger
+
+	mutex sync.Mutex
+	// loaded is a list of routes registered by the previous load.
+	loaded []*Route
+	// modTime and size of the loaded file.
+	modTime time.Time
+	size    int64
+}
+
+// NewLoader returns a new loader of the routes file.
+// File format is detected by content, JSON is a subset of YAML.
+func NewLoader(r *router.Router, path string, registry Registry) *Loader {
+	return &Loader{
+		router:   r,
+		path:     path,
+		registry: registry,
+	}
+}
+
+// Load reads the file and replaces routes of the previous load
+// in one atomic update. Routes registered by other code are not changed.
+// On error router is not changed and error has the file path and line.
+func (l *Loader) Load() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	info, err := os.Stat(l.path)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		return err
+	}
+
+	// file with errors is not reloaded until next change
+	l.modTime = info.ModTime()
+	l.size = info.Size()
+
+	c, err := Parse(data)
+	if err != nil {
+		return l.withFile(err)
+	}
+
+	err = l.router.Update(func(tx *router.Tx) error {
+		for _, route := range l.loaded {
+			tx.RemoveMethod(route.Method, route.Pattern)
+		}
+
+		return c.Apply(tx, l.registry)
+	})
+	if err != nil {
+		return l.withFile(err)
+	}
+
+	l.loaded = c.Routes
+
+	return nil
+}
+
+// withFile sets file path for configuration errors.
+func (l *Loader) withFile(err error) error {
+	setFile(err, l.path)
+	return err
+}
+
+// setFile sets file path for each configuration error in the error tree.
+// Errors of the route are joined, and joined again with other routes.
+func setFile(err error, file string) {
+	switch e := err.(type) {
+	case *Error:
+		e.File = file
+	case interface{ Unwrap() []error }:
+		for _, err := range e.Unwrap() {
+			setFile(err, file)
+		}
+	case interface{ Unwrap() error }:
+		setFile(e.Unwrap(), file)
+	}
+}
+
+// changed checks if the file is changed since the last load.
+func (l *Loader) changed() bool {
+	info, err := os.Stat(l.path)
+	if err != nil {
+		return false
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return !info.ModTime().Equal(l.modTime) || info.Size() != l.size
+}
+
+// Watch reloads routes on SIGHUP signal and when the file is changed.
+// File is checked with the interval, if interval is 0 only signal is used.
+// Reload errors are logged, the router keeps the previous routes.
+// Watch returns when ctx is done.
+func (l *Loader) Watch(ctx context.Context, interval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-hup:
+			l.reload()
+
+		case <-tick:
+			if l.changed() {
+				l.reload()
+			}
+		}
+	}
+}
+
+func (l *Loader) reload() {
+	if err := l.Load(); err != nil {
+		l.logf("config: reload failed: %v", err)
+	}
+}
+
+func (l *Loader) logf(format string, args ...any) {
+	if l.ErrorLog != nil {
+		l.ErrorLog.Printf(format, args...)
+	} else {
+		log.Printf(format, args...)
+	}
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cesbo/go-router"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoader_Load(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "routes.yaml")
+	write := func(data string) {
+		assert.NoError(os.WriteFile(path, []byte(data), 0o644))
+	}
+
+	r := router.NewRouter()
+	r.Get("/app", func(w http.ResponseWriter, r *http.Request) {})
+
+	loader := NewLoader(r, path, nil)
+
+	write(`
+routes:
+  - pattern: /a
+    kind: status
+    code: 204
+  - pattern: /b
+    kind: redirect
+    target: /a
+`)
+	assert.NoError(loader.Load())
+	assert.Equal(http.StatusNoContent, serve(r, http.MethodGet, "/a").Code)
+	assert.Equal(http.StatusMovedPermanently, serve(r, http.MethodGet, "/b").Code)
+
+	// routes of the previous load are replaced
+	write(`
+routes:
+  - pattern: /a
+    kind: status
+    code: 202
+`)
+	assert.NoError(loader.Load())
+	assert.Equal(http.StatusAccepted, serve(r, http.MethodGet, "/a").Code)
+	assert.Equal(http.StatusNotFound, serve(r, http.MethodGet, "/b").Code)
+	assert.NotNil(r.Lookup("/app"))
+
+	// router is not changed on error
+	write(`
+routes:
+  - pattern: /c
+    kind: status
+    code: 204
+  - pattern: /app
+    method: GET
+    kind: status
+    code: 204
+`)
+	err := loader.Load()
+	if assert.Error(err) {
+		assert.True(strings.HasPrefix(err.Error(), "config: "+path+":6: "), err.Error())
+	}
+	assert.Equal(http.StatusAccepted, serve(r, http.MethodGet, "/a").Code)
+	assert.Nil(r.Lookup("/c"))
+
+	// all errors of the route have a file
+	write(`
+routes:
+  - kind: bogus
+    extra: 1
+`)
+	err = loader.Load()
+	if assert.Error(err) {
+		lines := strings.Split(err.Error(), "\n")
+		assert.Len(lines, 3)
+		for _, line := range lines {
+			assert.True(strings.HasPrefix(line, "config: "+path+":"), line)
+		}
+	}
+}
+
+func TestLoader_Watch(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "routes.json")
+	assert.NoError(os.WriteFile(path, []byte(`{"routes": []}`), 0o644))
+
+	r := router.NewRouter()
+	loader := NewLoader(r, path, nil)
+	loader.ErrorLog = log.New(io.Discard, "", 0)
+	assert.NoError(loader.Load())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		loader.Watch(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+
+	events := r.Events(1)
+	defer events.Cancel()
+
+	assert.NoError(os.WriteFile(path, []byte(`{
+  "routes": [
+    {"pattern": "/a", "kind": "status", "code": 204}
+  ]
+}`), 0o644))
+
+	select {
+	case event := <-events.C:
+		assert.Equal(router.EventHandle, event.Kind)
+		assert.Equal("/a", event.Pattern)
+	case <-time.After(5 * time.Second):
+		t.Error("routes are not reloaded")
+	}
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,197 @@
+package router
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// ConflictError is returned by TryHandle if the new route conflicts
+// with a registered route.
+type ConflictError struct {
+	Method  string
+	Pattern string
+	// Source is a call site of the new route registration: file:line
+	Source string
+
+	ExistingMethod  string
+	ExistingPattern string
+	ExistingSource  string
+
+	// Shadow is true if the new route makes the existing route
+	// of the mounted router unreachable.
+	Shadow bool
+	// Params is true if the existing route for other method has
+	// the same pattern with different parameter names.
+	// Otherwise the route with the same pattern and method is registered.
+	Params bool
+}
+
+func formatRoute(method, pattern string) string {
+	if method == "" {
+		return pattern
+	}
+
+	return method + " " + pattern
+}
+
+func (e *ConflictError) Error() string {
+	if e.Shadow {
+		return fmt.Sprintf(
+			"router: %s at %s shadows %s at %s",
+			formatRoute(e.Method, e.Pattern),
+			e.Source,
+			formatRoute(e.ExistingMethod, e.ExistingPattern),
+			e.ExistingSource,
+		)
+	}
+
+	if e.Params {
+		return fmt.Sprintf(
+			"router: %s at %s has parameter names different from %s at %s",
+			formatRoute(e.Method, e.Pattern),
+			e.Source,
+			formatRoute(e.ExistingMethod, e.ExistingPattern),
+			e.ExistingSource,
+		)
+	}
+
+	return fmt.Sprintf(
+		"router: %s at %s is already registered at %s",
+		formatRoute(e.Method, e.Pattern),
+		e.Source,
+		e.ExistingSource,
+	)
+}
+
+const packagePrefix = "github.com/cesbo/go-router."
+
+// caller returns file:line of the first caller outside of the package.
+func caller() string {
+	pc := make([]uintptr, 16)
+	n := runtime.Callers(2, pc)
+	frames := runtime.CallersFrames(pc[:n])
+
+	for {
+		f, more := frames.Next()
+		if !strings.HasPrefix(f.Function, packagePrefix) || strings.HasSuffix(f.File, "_test.go") {
+			return fmt.Sprintf("%s:%d", f.File, f.Line)
+		}
+
+		if !more {
+			return "unknown"
+		}
+	}
+}
+
+// normalizePattern returns pattern path without parameter names:
+// /users/{id} and /users/{uid} are both /users/{}
+func normalizePattern(pattern string) string {
+	p, err := parsePattern(pattern)
+	if err != nil || p == nil {
+		return pattern
+	}
+
+	var b strings.Builder
+	key := p.path()
+	for {
+		l := strings.IndexByte(key, '{')
+		if l == -1 {
+			b.WriteString(key)
+			return b.String()
+		}
+
+		b.WriteString(key[:l+1])
+		key = key[l+strings.IndexByte(key[l:], '}'):]
+	}
+}
+
+// paramsConflict checks if handlers of the route old, except handler
+// replaced by the new endpoint, are registered with other parameter names.
+func (t *table) paramsConflict(old *route, method, pattern string, e *endpoint) *ConflictError {
+	existing, ok := t.radix.registeredPattern(pattern)
+	if !ok || existing == pattern {
+		return nil
+	}
+
+	var err *ConflictError
+
+	old.each(func(m string, v *endpoint) bool {
+		if m == method && v.conditions == e.conditions {
+			return true
+		}
+
+		err = &ConflictError{
+			Method:          method,
+			Pattern:         withQuery(pattern, e.query),
+			Source:          e.source,
+			ExistingMethod:  m,
+			ExistingPattern: withQuery(existing, v.query),
+			ExistingSource:  v.source,
+			Params:          true,
+		}
+		return false
+	})
+
+	return err
+}
+
+// withQuery returns pattern with query conditions.
+func withQuery(pattern, query string) string {
+	if query == "" {
+		return pattern
+	}
+
+	return pattern + "?" + query
+}
+
+// conflict checks if the new endpoint conflicts with registered routes.
+// Radix is a tree with the new endpoint. Pattern is without query conditions.
+func (t *table) conflict(radix *Radix[*route], method, pattern string, e *endpoint) *ConflictError {
+	full := withQuery(pattern, e.query)
+
+	if rt, ok := t.radix.LookupPattern(pattern); ok {
+		if old, ok := rt.lookup(method, e.conditions); ok {
+			return &ConflictError{
+				Method:          method,
+				Pattern:         full,
+				Source:          e.source,
+				ExistingMethod:  method,
+				ExistingPattern: full,
+				ExistingSource:  old.source,
+			}
+		}
+	}
+
+	// routes of the mounted routers served by the new route
+	var err *ConflictError
+
+	t.walk("", func(m, p string, old *endpoint) bool {
+		path, _ := splitQuery(p)
+		match, ok := radix.MatchPath(path)
+		if !ok {
+			return true
+		}
+
+		// keys are compared without parameter names,
+		// route of the same pattern is not shadowed
+		if key := normalizePattern(match.Key); key == normalizePattern(pattern) {
+			if before, ok := t.radix.MatchPath(path); ok && normalizePattern(before.Key) != key {
+				err = &ConflictError{
+					Method:          method,
+					Pattern:         full,
+					Source:          e.source,
+					ExistingMethod:  m,
+					ExistingPattern: p,
+					ExistingSource:  old.source,
+					Shadow:          true,
+				}
+				return false
+			}
+		}
+
+		return true
+	})
+
+	return err
+}
@@ -0,0 +1,104 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouter_TryHandle(t *testing.T) {
+	assert := assert.New(t)
+	router := NewRouter()
+
+	assert.NoError(router.TryHandle("/api/users", testHandler(1)))
+	assert.NoError(router.TryHandleMethod(http.MethodPost, "/api/users", testHandler(2)))
+
+	err := router.TryHandle("/api/users", testHandler(3))
+	var conflict *ConflictError
+	if assert.True(errors.As(err, &conflict)) {
+		assert.False(conflict.Shadow)
+		assert.Equal("/api/users", conflict.ExistingPattern)
+		assert.True(strings.HasSuffix(conflict.ExistingSource, "conflict_test.go:16"), conflict.ExistingSource)
+		assert.True(strings.HasSuffix(conflict.Source, "conflict_test.go:19"), conflict.Source)
+		assert.Contains(err.Error(), "router: /api/users at ")
+		assert.Contains(err.Error(), " is already registered at ")
+	}
+
+	// router is not changed
+	assert.Equal(testHandler(1), router.Lookup("/api/users"))
+
+	// same pattern with other parameter names
+	assert.NoError(router.TryHandle("/streams/{id}", testHandler(4)))
+	assert.Error(router.TryHandle("/streams/{name}", testHandler(5)))
+
+	// catch-all is a directory
+	assert.NoError(router.TryHandle("/static/", testHandler(6)))
+	assert.Error(router.TryHandle("/static/{file...}", testHandler(7)))
+
+	// Handle replaces by default
+	router.Handle("/api/users", testHandler(8))
+	assert.Equal(testHandler(8), router.Lookup("/api/users"))
+}
+
+func TestRouter_TryHandle_params(t *testing.T) {
+	assert := assert.New(t)
+	router := NewRouter()
+
+	router.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {})
+
+	err := router.TryHandleMethod(http.MethodPut, "/users/{x}", testHandler(1))
+	var conflict *ConflictError
+	if assert.True(errors.As(err, &conflict)) {
+		assert.True(conflict.Params)
+		assert.False(conflict.Shadow)
+		assert.Equal(http.MethodGet, conflict.ExistingMethod)
+		assert.Equal("/users/{id}", conflict.ExistingPattern)
+		assert.True(strings.HasSuffix(conflict.ExistingSource, "conflict_test.go:50"), conflict.ExistingSource)
+		assert.Contains(err.Error(), "router: PUT /users/{x} at ")
+		assert.Contains(err.Error(), " has parameter names different from GET /users/{id} at ")
+	}
+
+	// same names
+	assert.NoError(router.TryHandleMethod(http.MethodPut, "/users/{id}", testHandler(1)))
+}
+
+func TestRouter_TryHandle_shadow(t *testing.T) {
+	assert := assert.New(t)
+
+	api := NewRouter()
+	api.Get("/admin/users", func(w http.ResponseWriter, r *http.Request) {})
+	api.Get("/public/", func(w http.ResponseWriter, r *http.Request) {})
+
+	root := NewRouter()
+	root.Mount("/api/", api)
+
+	// not overlapped with mounted routes
+	assert.NoError(root.TryHandle("/api/health", testHandler(1)))
+
+	err := root.TryHandle("/api/admin/", testHandler(2))
+	var conflict *ConflictError
+	if assert.True(errors.As(err, &conflict)) {
+		assert.True(conflict.Shadow)
+		assert.Equal(http.MethodGet, conflict.ExistingMethod)
+		assert.Equal("/api/admin/users", conflict.ExistingPattern)
+		assert.True(strings.HasSuffix(conflict.ExistingSource, "conflict_test.go:72"), conflict.ExistingSource)
+		assert.Contains(err.Error(), "shadows GET /api/admin/users at ")
+	}
+
+	assert.Error(root.TryHandleMethod(http.MethodPost, "/api/public/", testHandler(3)))
+
+	// strict mode
+	root.Strict = true
+	assert.Panics(func() {
+		root.Handle("/api/health", testHandler(4))
+	})
+	assert.Panics(func() {
+		root.Handle("/api/admin/users", testHandler(5))
+	})
+	assert.NotPanics(func() {
+		root.Handle("/api/admin/groups", testHandler(6))
+	})
+}
@@ -0,0 +1,281 @@
+package router
+
+import (
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EventKind is a kind of the routing table change.
+type EventKind int
+
+const (
+	// EventHandle is sent when a handler is registered or replaced.
+	EventHandle EventKind = iota + 1
+	// EventRemove is sent when a handler is removed or expired.
+	EventRemove
+	// EventUse is sent when router or group middleware is appended.
+	EventUse
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventHandle:
+		return "handle"
+	case EventRemove:
+		return "remove"
+	case EventUse:
+		return "use"
+	default:
+		return "unknown"
+	}
+}
+
+// Event describes a change of the routing table.
+type Event struct {
+	Kind EventKind
+	// Method is a request method.
+	// It is empty for handlers registered for any method.
+	Method string
+	// Pattern is a route pattern with query conditions.
+	// For EventUse it is a group prefix or empty for router middleware.
+	Pattern string
+	// Old is a replaced or removed handler.
+	Old http.Handler
+	// New is a registered handler.
+	New http.Handler
+	// Time is a time when the change is published.
+	Time time.Time
+}
+
+// observer is a subscriber for the router events.
+type observer struct {
+	fn func(Event)
+}
+
+// Observe subscribes fn for changes of the routing table.
+// Returns function to cancel the subscription.
+//
+// Observers are called sequentially in order of changes after the change
+// is published and the router lock is released, so observer could use and
+// change the router. Events of the changes made by observer are delivered
+// after the current event. Observer is called by the goroutine that made
+// the change, so slow observer delays the return of this call and delivery
+// of next events. Use Events for delivery that does not wait for the reader.
+func (r *Router) Observe(fn func(Event)) (cancel func()) {
+	o := &observer{
+		fn: fn,
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// full slice expression to not share array with the dispatching list
+	r.observers = append(r.observers[:len(r.observers):len(r.observers)], o)
+
+	return func() {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+
+		r.observers = slices.DeleteFunc(slices.Clone(r.observers), func(v *observer) bool {
+			return v == o
+		})
+	}
+}
+
+// Subscription is a channel subscription for the router events.
+type Subscription struct {
+	// C is a channel with events. It is closed on Cancel.
+	C <-chan Event
+
+	mutex   sync.Mutex
+	ch      chan Event
+	closed  bool
+	dropped atomic.Uint64
+	stop    func()
+}
+
+// Events subscribes channel with the given buffer size for changes
+// of the routing table.
+// Delivery never waits for the reader: if channel buffer is full,
+// event is dropped and counted in Dropped, so slow reader does not
+// delay router changes and other observers.
+func (r *Router) Events(size int) *Subscription {
+	ch := make(chan Event, size)
+	s := &Subscription{
+		C:  ch,
+		ch: ch,
+	}
+	s.stop = r.Observe(s.send)
+
+	return s
+}
+
+func (s *Subscription) send(event Event) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	select {
+	case s.ch <- event:
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+// Dropped returns number of events dropped because channel buffer was full.
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Cancel cancels the subscription and closes the channel.
+func (s *Subscription) Cancel() {
+	s.stop()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+	}
+}
+
+// record appends event to the table changes.
+// Events are recorded only if router has observers.
+func (t *table) record(event Event) {
+	if t.events != nil {
+		*t.events = append(*t.events, event)
+	}
+}
+
+// changed records events for the route changes.
+// Route could be nil.
+func (t *table) changed(path string, old, next *route) {
+	if t.events == nil {
+		return
+	}
+
+	pattern := func(e *endpoint) string {
+		if e.query != "" {
+			return path + "?" + e.query
+		}
+		return path
+	}
+
+	if old != nil {
+		old.each(func(method string, e *endpoint) bool {
+			if next != nil {
+				if _, ok := next.lookup(method, e.conditions); ok {
+					return true
+				}
+			}
+
+			t.record(Event{
+				Kind:    EventRemove,
+				Method:  method,
+				Pattern: pattern(e),
+				Old:     e.handler,
+			})
+			return true
+		})
+	}
+
+	if next != nil {
+		next.each(func(method string, e *endpoint) bool {
+			event := Event{
+				Kind:    EventHandle,
+				Method:  method,
+				Pattern: pattern(e),
+				New:     e.handler,
+			}
+
+			if old != nil {
+				if prev, ok := old.lookup(method, e.conditions); ok {
+					if prev == e {
+						return true
+					}
+					event.Old = prev.handler
+				}
+			}
+
+			t.record(event)
+			return true
+		})
+	}
+}
+
+// publish stores the table and queues its events.
+// Returns true if caller should dispatch events.
+// Should be called with locked mutex.
+func (r *Router) publish(t *table) bool {
+	events := t.events
+	t.events = nil
+	r.table.Store(t)
+
+	if events == nil || len(*events) == 0 {
+		return false
+	}
+
+	now := r.clock().Now()
+	for i := range *events {
+		(*events)[i].Time = now
+	}
+	r.pending = append(r.pending, *events...)
+
+	if r.dispatching {
+		// events are delivered by the current dispatcher
+		return false
+	}
+
+	r.dispatching = true
+	return true
+}
+
+// observe prepares table to record events if router has observers.
+// Should be called with locked mutex.
+func (r *Router) observe(t *table) {
+	if len(r.observers) != 0 {
+		t.events = new([]Event)
+	}
+}
+
+// dispatch delivers pending events to observers without lock.
+func (r *Router) dispatch() {
+	done := false
+	defer func() {
+		// observer panics, next change starts a new dispatcher
+		if !done {
+			r.mutex.Lock()
+			r.dispatching = false
+			r.mutex.Unlock()
+		}
+	}()
+
+	for {
+		r.mutex.Lock()
+		events := r.pending
+		observers := r.observers
+		r.pending = nil
+		if len(events) == 0 {
+			r.dispatching = false
+		}
+		r.mutex.Unlock()
+
+		if len(events) == 0 {
+			done = true
+			return
+		}
+
+		for _, event := range events {
+			for _, o := range observers {
+				o.fn(event)
+			}
+		}
+	}
+}
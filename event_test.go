@@ -0,0 +1,150 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouter_Observe(t *testing.T) {
+	assert := assert.New(t)
+	clock := newTestClock()
+	router := NewRouter()
+	router.Clock = clock
+
+	var events []Event
+	cancel := router.Observe(func(event Event) {
+		events = append(events, event)
+	})
+
+	mw := MiddlewareFunc(func(next http.Handler) http.Handler { return next })
+
+	router.Handle("/a", testHandler(1))
+	router.Handle("/a", testHandler(2))
+	router.HandleMethod(http.MethodPost, "/a?beta", testHandler(3))
+	router.Use(mw)
+	router.Group("/api", func(g *Group) {
+		g.Use(mw)
+	})
+	router.Remove("/a")
+
+	now := clock.Now()
+	assert.Equal([]Event{
+		{Kind: EventHandle, Pattern: "/a", New: testHandler(1), Time: now},
+		{Kind: EventHandle, Pattern: "/a", Old: testHandler(1), New: testHandler(2), Time: now},
+		{Kind: EventHandle, Method: http.MethodPost, Pattern: "/a?beta", New: testHandler(3), Time: now},
+		{Kind: EventUse, Time: now},
+		{Kind: EventUse, Pattern: "/api", Time: now},
+		{Kind: EventRemove, Pattern: "/a", Old: testHandler(2), Time: now},
+		{Kind: EventRemove, Method: http.MethodPost, Pattern: "/a?beta", Old: testHandler(3), Time: now},
+	}, events)
+
+	cancel()
+	router.Handle("/b", testHandler(4))
+	assert.Len(events, 7)
+}
+
+func TestRouter_Observe_update(t *testing.T) {
+	assert := assert.New(t)
+	router := NewRouter()
+	router.Handle("/a", testHandler(1))
+
+	var events []Event
+	router.Observe(func(event Event) {
+		events = append(events, event)
+	})
+
+	// events of the rolled back transaction are not delivered
+	router.Update(func(tx *Tx) error {
+		tx.Handle("/b", testHandler(2))
+		return errors.New("rollback")
+	})
+	assert.Empty(events)
+
+	router.Update(func(tx *Tx) error {
+		tx.Remove("/a")
+		tx.Handle("/b", testHandler(2))
+		return nil
+	})
+	if assert.Len(events, 2) {
+		assert.Equal(EventRemove, events[0].Kind)
+		assert.Equal("/a", events[0].Pattern)
+		assert.Equal(EventHandle, events[1].Kind)
+		assert.Equal("/b", events[1].Pattern)
+	}
+}
+
+func TestRouter_Observe_expire(t *testing.T) {
+	assert := assert.New(t)
+	clock := newTestClock()
+	router := NewRouter()
+	router.Clock = clock
+
+	router.HandleTTL("/a", testHandler(1), time.Second)
+
+	var events []Event
+	router.Observe(func(event Event) {
+		events = append(events, event)
+	})
+
+	clock.Advance(time.Second)
+	assert.Equal([]Event{
+		{Kind: EventRemove, Pattern: "/a", Old: testHandler(1), Time: clock.Now()},
+	}, events)
+}
+
+func TestRouter_Observe_reentrant(t *testing.T) {
+	assert := assert.New(t)
+	router := NewRouter()
+
+	var patterns []string
+	router.Observe(func(event Event) {
+		patterns = append(patterns, event.Pattern)
+
+		// observer changes the router without deadlock,
+		// event is delivered after the current one
+		if event.Pattern == "/a" {
+			router.Handle("/b", testHandler(2))
+			assert.Equal(testHandler(2), router.Lookup("/b"))
+		}
+	})
+
+	router.Handle("/a", testHandler(1))
+	assert.Equal([]string{"/a", "/b"}, patterns)
+}
+
+func TestRouter_Events(t *testing.T) {
+	assert := assert.New(t)
+	clock := newTestClock()
+	router := NewRouter()
+	router.Clock = clock
+
+	events := router.Events(2)
+
+	// writers are not blocked by the reader
+	for i := 0; i < 4; i++ {
+		router.Handle("/r/"+strconv.Itoa(i), testHandler(i))
+	}
+	router.HandleTTL("/ttl", testHandler(5), time.Second)
+	clock.Advance(time.Second)
+	assert.Nil(router.Lookup("/ttl"))
+
+	assert.Equal(uint64(4), events.Dropped())
+
+	events.Cancel()
+	events.Cancel()
+
+	var patterns []string
+	for event := range events.C {
+		patterns = append(patterns, event.Pattern)
+	}
+	assert.Equal([]string{"/r/0", "/r/1"}, patterns)
+
+	// no delivery after cancel
+	router.Handle("/b", testHandler(1))
+	assert.Equal(uint64(4), events.Dropped())
+}
@@ -21,26 +21,33 @@ func (userContext) String() string {
 // Key for request Context
 var userContextKey = &userContext{}
 
-var loginForm = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+var app = router.NewRouter()
+
+// Template function to build URL for the named route
+var funcs = template.FuncMap{
+	"url": app.URL,
+}
+
+var loginForm = template.Must(template.New("login").Funcs(funcs).Parse(`<!DOCTYPE html>
 <html>
 <head>
 	<title>Login</title>
 </head>
 <body>
-	<form method="POST" action="/login">
+	<form method="POST" action="{{ url "login" }}">
 		<input type="text" name="name" placeholder="Name" />
 		<input type="submit" value="Login" />
 	</form>
 </body>
 </html>`))
 
-var helloForm = template.Must(template.New("hello").Parse(`<!DOCTYPE html>
+var helloForm = template.Must(template.New("hello").Funcs(funcs).Parse(`<!DOCTYPE html>
 <html>
 <head>
 	<title>Hello</title>
 </head>
 <body>
-	<form method="POST" action="/logout">
+	<form method="POST" action="{{ url "logout" }}">
 		<b>Hello, {{ .Name }}!</b>
 		<input type="submit" value="Logout" />
 	</form>
@@ -67,6 +74,16 @@ func AuthMW(next http.Handler) http.Handler {
 	})
 }
 
+// redirect replies with redirect to the named route
+func redirect(w http.ResponseWriter, r *http.Request, name string) {
+	url, err := app.URL(name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, url, http.StatusFound)
+}
+
 func index(w http.ResponseWriter, r *http.Request) {
 	user, ok := r.Context().Value(userContextKey).(*userContext)
 	if !ok {
@@ -80,11 +97,6 @@ func index(w http.ResponseWriter, r *http.Request) {
 }
 
 func login(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		return
-	}
-
 	if err := r.ParseForm(); err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		return
@@ -99,15 +111,10 @@ func login(w http.ResponseWriter, r *http.Request) {
 			Expires: time.Now().Add(5 * time.Minute),
 		},
 	)
-	http.Redirect(w, r, "/", http.StatusFound)
+	redirect(w, r, "index")
 }
 
 func logout(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		return
-	}
-
 	http.SetCookie(
 		w,
 		&http.Cookie{
@@ -117,20 +124,18 @@ func logout(w http.ResponseWriter, r *http.Request) {
 			MaxAge: -1,
 		},
 	)
-	http.Redirect(w, r, "/", http.StatusFound)
+	redirect(w, r, "index")
 }
 
 func main() {
-	r := router.NewRouter()
-
 	// Add middleware
-	r.Use(AuthMW)
+	app.Use(AuthMW)
 
 	// Add routes
-	r.HandleFunc("/", index)
-	r.HandleFunc("/login", login)
-	r.HandleFunc("/logout", logout)
+	app.HandleFunc("/", index, router.Name("index"))
+	app.Post("/login", login, router.Name("login"))
+	app.Post("/logout", logout, router.Name("logout"))
 
 	// Start server
-	http.ListenAndServe(":8080", r)
+	http.ListenAndServe(":8080", app)
 }
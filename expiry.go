@@ -0,0 +1,165 @@
+package router
+
+import (
+	"container/heap"
+	"net/http"
+	"time"
+)
+
+// Clock is a source of time for the route expiry.
+// It could be replaced in tests.
+type Clock interface {
+	Now() time.Time
+	// AfterFunc calls f in its own goroutine after the duration elapses.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a timer made by Clock.
+type Timer interface {
+	Stop() bool
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+type expireOption func(pattern string)
+
+func (fn expireOption) apply(e *endpoint) {
+	e.onExpire = fn
+}
+
+// OnExpire returns option with callback for the route expiry.
+// Callback is called after the route is removed.
+func OnExpire(fn func(pattern string)) Option {
+	return expireOption(fn)
+}
+
+// expiry is a deadline for the registered endpoint.
+type expiry struct {
+	deadline time.Time
+	method   string
+	pattern  string
+	path     string
+	e        *endpoint
+}
+
+// expiryQueue is a min-heap of deadlines.
+type expiryQueue []*expiry
+
+func (q expiryQueue) Len() int           { return len(q) }
+func (q expiryQueue) Less(i, j int) bool { return q[i].deadline.Before(q[j].deadline) }
+func (q expiryQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *expiryQueue) Push(x any)        { *q = append(*q, x.(*expiry)) }
+
+func (q *expiryQueue) Pop() any {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return item
+}
+
+func (r *Router) clock() Clock {
+	if r.Clock != nil {
+		return r.Clock
+	}
+
+	return systemClock{}
+}
+
+// HandleTTL registers the handler for the given pattern same as Handle.
+// Route is removed after ttl.
+func (r *Router) HandleTTL(pattern string, handler http.Handler, ttl time.Duration, opts ...Option) {
+	r.HandleUntil(pattern, handler, r.clock().Now().Add(ttl), opts...)
+}
+
+// HandleUntil registers the handler for the given pattern same as Handle.
+// Route is removed at the deadline.
+// If route is replaced or removed before the deadline, it is not affected.
+// All routes share one timer for the nearest deadline.
+func (r *Router) HandleUntil(pattern string, handler http.Handler, deadline time.Time, opts ...Option) {
+	path, e := newRouteEndpoint(pattern, handler, opts)
+	e.expiry = &expiry{
+		deadline: deadline,
+		pattern:  pattern,
+		path:     path,
+		e:        e,
+	}
+
+	var err error
+
+	r.update(func(t *table) {
+		if err = t.handle("", path, e, r.Strict); err != nil {
+			return
+		}
+
+		heap.Push(&r.expiry, e.expiry)
+		if r.expiry[0] == e.expiry {
+			r.schedule()
+		}
+	})
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+// schedule starts timer for the nearest deadline.
+// Should be called with locked mutex.
+func (r *Router) schedule() {
+	if r.timer != nil {
+		r.timer.Stop()
+		r.timer = nil
+	}
+
+	if len(r.expiry) == 0 {
+		return
+	}
+
+	clock := r.clock()
+	r.timer = clock.AfterFunc(r.expiry[0].deadline.Sub(clock.Now()), r.expire)
+}
+
+// expire removes routes with passed deadline.
+func (r *Router) expire() {
+	var expired []*expiry
+
+	r.update(func(t *table) {
+		now := r.clock().Now()
+
+		for len(r.expiry) != 0 && !r.expiry[0].deadline.After(now) {
+			item := heap.Pop(&r.expiry).(*expiry)
+
+			rt, ok := t.radix.LookupPattern(item.path)
+			if !ok {
+				continue
+			}
+
+			// route could be replaced with the same pattern
+			e, ok := rt.lookup(item.method, item.e.conditions)
+			if !ok || e.expiry != item {
+				continue
+			}
+
+			t.set(item.path, rt.withoutVariant(item.method, e.conditions))
+			expired = append(expired, item)
+		}
+
+		r.schedule()
+	})
+
+	// callbacks are called without lock, so they could change the router
+	for _, item := range expired {
+		if item.e.onExpire != nil {
+			item.e.onExpire(item.pattern)
+		}
+	}
+}
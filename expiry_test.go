@@ -0,0 +1,206 @@
+package router
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testClock is a manual clock, timers are fired on Advance.
+type testClock struct {
+	mutex  sync.Mutex
+	now    time.Time
+	timers []*testTimer
+}
+
+type testTimer struct {
+	clock    *testClock
+	deadline time.Time
+	f        func()
+}
+
+func (c *testClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.now
+}
+
+func (c *testClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	t := &testTimer{
+		clock:    c,
+		deadline: c.now.Add(d),
+		f:        f,
+	}
+	c.timers = append(c.timers, t)
+
+	return t
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	c.now = c.now.Add(d)
+
+	var due []*testTimer
+	active := c.timers[:0]
+	for _, t := range c.timers {
+		if t.deadline.After(c.now) {
+			active = append(active, t)
+		} else {
+			due = append(due, t)
+		}
+	}
+	c.timers = active
+	c.mutex.Unlock()
+
+	for _, t := range due {
+		t.f()
+	}
+}
+
+func (c *testClock) pending() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return len(c.timers)
+}
+
+func (t *testTimer) Stop() bool {
+	c := t.clock
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for i, v := range c.timers {
+		if v == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+func newTestClock() *testClock {
+	return &testClock{
+		now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestRouter_HandleTTL(t *testing.T) {
+	assert := assert.New(t)
+	clock := newTestClock()
+	router := NewRouter()
+	router.Clock = clock
+
+	var expired []string
+	onExpire := OnExpire(func(pattern string) {
+		expired = append(expired, pattern)
+	})
+
+	router.HandleTTL("/a", testHandler(1), 10*time.Second, onExpire)
+	router.HandleTTL("/b", testHandler(2), 5*time.Second, onExpire)
+	router.HandleUntil("/c", testHandler(3), clock.Now().Add(20*time.Second), onExpire)
+	router.Handle("/d", testHandler(4))
+
+	// one shared timer
+	assert.Equal(1, clock.pending())
+
+	clock.Advance(5 * time.Second)
+	assert.Nil(router.Lookup("/b"))
+	assert.Equal(testHandler(1), router.Lookup("/a"))
+	assert.Equal([]string{"/b"}, expired)
+
+	clock.Advance(5 * time.Second)
+	assert.Nil(router.Lookup("/a"))
+	assert.Equal(testHandler(3), router.Lookup("/c"))
+	assert.Equal([]string{"/b", "/a"}, expired)
+
+	clock.Advance(time.Minute)
+	assert.Nil(router.Lookup("/c"))
+	assert.Equal(testHandler(4), router.Lookup("/d"))
+	assert.Equal([]string{"/b", "/a", "/c"}, expired)
+	assert.Equal(0, clock.pending())
+}
+
+func TestRouter_HandleTTL_replaced(t *testing.T) {
+	assert := assert.New(t)
+	clock := newTestClock()
+	router := NewRouter()
+	router.Clock = clock
+
+	called := false
+	router.HandleTTL("/a", testHandler(1), time.Second, OnExpire(func(string) {
+		called = true
+	}))
+	router.HandleTTL("/b", testHandler(2), time.Second)
+
+	// replaced route is not expired
+	router.Handle("/a", testHandler(3))
+	// removed and registered again
+	router.Remove("/b")
+	router.Handle("/b", testHandler(4))
+
+	clock.Advance(time.Second)
+	assert.Equal(testHandler(3), router.Lookup("/a"))
+	assert.Equal(testHandler(4), router.Lookup("/b"))
+	assert.False(called)
+}
+
+func TestRouter_HandleTTL_variant(t *testing.T) {
+	assert := assert.New(t)
+	clock := newTestClock()
+	router := NewRouter()
+	router.Clock = clock
+
+	router.Handle("/api", testHandler(1))
+	router.HandleTTL("/api?beta", testHandler(2), time.Second)
+
+	clock.Advance(time.Second)
+	assert.Equal(testHandler(1), router.Lookup("/api"))
+
+	count := 0
+	for range router.Routes() {
+		count++
+	}
+	assert.Equal(1, count)
+}
+
+func TestRouter_HandleTTL_callback(t *testing.T) {
+	assert := assert.New(t)
+	clock := newTestClock()
+	router := NewRouter()
+	router.Clock = clock
+
+	// callback could change the router
+	router.HandleTTL("/a", testHandler(1), time.Second, OnExpire(func(pattern string) {
+		router.HandleTTL(pattern, testHandler(2), time.Second)
+	}))
+
+	clock.Advance(time.Second)
+	assert.Equal(testHandler(2), router.Lookup("/a"))
+
+	clock.Advance(time.Second)
+	assert.Nil(router.Lookup("/a"))
+}
+
+func TestRouter_HandleTTL_system(t *testing.T) {
+	assert := assert.New(t)
+	router := NewRouter()
+
+	done := make(chan struct{})
+	router.HandleTTL("/a", testHandler(1), time.Millisecond, OnExpire(func(string) {
+		close(done)
+	}))
+
+	select {
+	case <-done:
+		assert.Nil(router.Lookup("/a"))
+	case <-time.After(time.Second):
+		t.Fatal("route is not expired")
+	}
+}
@@ -0,0 +1,86 @@
+package router
+
+import (
+	"net/http"
+)
+
+// Group is a set of routes with common prefix and middleware.
+type Group struct {
+	router *Router
+	prefix string
+}
+
+// Group calls fn with the group of routes with the given prefix.
+// Patterns registered in the group are appended to the prefix.
+// Group middleware is called for routes with pattern started with
+// the group prefix, including routes registered outside of the group.
+func (r *Router) Group(prefix string, fn func(g *Group)) {
+	fn(&Group{
+		router: r,
+		prefix: prefix,
+	})
+}
+
+// Group calls fn with the nested group.
+// Prefix is appended to the current group prefix.
+func (g *Group) Group(prefix string, fn func(g *Group)) {
+	g.router.Group(g.prefix+prefix, fn)
+}
+
+// Use appends middleware to the group.
+// Group middleware is called after router middleware and middleware
+// of the parent groups, but before route middleware.
+func (g *Group) Use(mw ...MiddlewareFunc) {
+	g.router.update(func(t *table) {
+		t.groupUse(g.prefix, mw)
+	})
+}
+
+// groupUse appends middleware to the group prefix.
+func (t *table) groupUse(prefix string, mw []MiddlewareFunc) {
+	list, _ := t.groups.LookupPattern(prefix)
+	list = append(list[:len(list):len(list)], mw...)
+	t.groups, _, _ = t.groups.insertPattern(prefix, list, true)
+	t.recompose()
+	t.record(Event{Kind: EventUse, Pattern: prefix})
+}
+
+// Handle registers the handler for the given pattern in the group.
+func (g *Group) Handle(pattern string, handler http.Handler, opts ...Option) {
+	g.router.Handle(g.prefix+pattern, handler, opts...)
+}
+
+// HandleFunc registers the handler function for the given pattern in the group.
+func (g *Group) HandleFunc(pattern string, handler http.HandlerFunc, opts ...Option) {
+	g.router.HandleFunc(g.prefix+pattern, handler, opts...)
+}
+
+// HandleMethod registers the handler for the given method and pattern in the group.
+func (g *Group) HandleMethod(method, pattern string, handler http.Handler, opts ...Option) {
+	g.router.HandleMethod(method, g.prefix+pattern, handler, opts...)
+}
+
+// Get registers the handler function for GET requests in the group.
+func (g *Group) Get(pattern string, handler http.HandlerFunc, opts ...Option) {
+	g.router.Get(g.prefix+pattern, handler, opts...)
+}
+
+// Post registers the handler function for POST requests in the group.
+func (g *Group) Post(pattern string, handler http.HandlerFunc, opts ...Option) {
+	g.router.Post(g.prefix+pattern, handler, opts...)
+}
+
+// Put registers the handler function for PUT requests in the group.
+func (g *Group) Put(pattern string, handler http.HandlerFunc, opts ...Option) {
+	g.router.Put(g.prefix+pattern, handler, opts...)
+}
+
+// Delete registers the handler function for DELETE requests in the group.
+func (g *Group) Delete(pattern string, handler http.HandlerFunc, opts ...Option) {
+	g.router.Delete(g.prefix+pattern, handler, opts...)
+}
+
+// Patch registers the handler function for PATCH requests in the group.
+func (g *Group) Patch(pattern string, handler http.HandlerFunc, opts ...Option) {
+	g.router.Patch(g.prefix+pattern, handler, opts...)
+}
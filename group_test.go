@@ -0,0 +1,93 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// traceMW appends name to the X-Trace header.
+func traceMW(name string) MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("X-Trace", name)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func TestRouter_Group(t *testing.T) {
+	assert := assert.New(t)
+	router := NewRouter()
+
+	router.Use(traceMW("global"))
+
+	router.Group("/admin", func(g *Group) {
+		g.Use(traceMW("admin"))
+
+		g.Get("/users", func(w http.ResponseWriter, r *http.Request) {}, traceMW("route"))
+
+		g.Group("/reports", func(g *Group) {
+			g.Use(traceMW("reports-1"), traceMW("reports-2"))
+			g.Handle("/daily", testHandler(1))
+		})
+	})
+
+	router.Group("/public", func(g *Group) {
+		g.HandleFunc("/index", func(w http.ResponseWriter, r *http.Request) {})
+	})
+
+	// group prefix is matched at the segment boundary
+	router.HandleFunc("/admin", func(w http.ResponseWriter, r *http.Request) {})
+	router.HandleFunc("/administrator", func(w http.ResponseWriter, r *http.Request) {})
+	router.HandleFunc("/admin-public/", func(w http.ResponseWriter, r *http.Request) {})
+
+	tests := []struct {
+		path  string
+		trace string
+	}{
+		{"/admin/users", "global,admin,route"},
+		{"/admin/reports/daily", "global,admin,reports-1,reports-2"},
+		{"/public/index", "global"},
+		{"/admin", "global,admin"},
+		{"/administrator", "global"},
+		{"/admin-public/", "global"},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, test.path, nil)
+		router.ServeHTTP(w, r)
+
+		trace := strings.Join(w.Header().Values("X-Trace"), ",")
+		if !assert.Equal(test.trace, trace, test.path) {
+			return
+		}
+	}
+
+	// not found is not affected by middleware
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/admin/not-found", nil)
+	router.ServeHTTP(w, r)
+	assert.Equal(http.StatusNotFound, w.Code)
+	assert.Empty(w.Header().Values("X-Trace"))
+}
+
+func TestRouter_Group_params(t *testing.T) {
+	assert := assert.New(t)
+	router := NewRouter()
+
+	router.Group("/streams/{id}", func(g *Group) {
+		g.Use(traceMW("stream"))
+	})
+	router.Get("/streams/{name}/sessions", func(w http.ResponseWriter, r *http.Request) {})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/streams/1/sessions", nil)
+	router.ServeHTTP(w, r)
+
+	assert.Equal([]string{"stream"}, w.Header().Values("X-Trace"))
+}
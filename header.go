@@ -0,0 +1,292 @@
+package router
+
+import (
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// headerMatcher is a condition for the request header.
+type headerMatcher struct {
+	key   string
+	value string
+}
+
+type headerOption headerMatcher
+
+func (h headerOption) apply(e *endpoint) {
+	e.headerMatch = append(e.headerMatch, headerMatcher(h))
+}
+
+// Header returns option to select the route by the request header.
+// If value is empty, header should be defined with any value,
+// otherwise value is compared case-insensitively.
+// Route with header conditions is a variant of the route
+// same as route with query conditions.
+func Header(key, value string) Option {
+	return headerOption{
+		key:   http.CanonicalHeaderKey(key),
+		value: value,
+	}
+}
+
+type acceptOption []string
+
+func (a acceptOption) apply(e *endpoint) {
+	e.accept = append(e.accept, a...)
+}
+
+// Accept returns option to select the route by the Accept header.
+// Types are media types produced by the handler: application/vnd.x.v2+json
+// Variant with the highest quality value in the Accept header is selected.
+// If no variant is acceptable, router replies with 406 status.
+func Accept(types ...string) Option {
+	return acceptOption(types)
+}
+
+type contentTypeOption []string
+
+func (c contentTypeOption) apply(e *endpoint) {
+	e.contentType = append(e.contentType, c...)
+}
+
+// ContentType returns option to select the route by the Content-Type header.
+// Types are media types consumed by the handler, type could be a wildcard: text/*
+// If no variant is matched, router replies with 415 status.
+func ContentType(types ...string) Option {
+	return contentTypeOption(types)
+}
+
+// prepareConditions makes canonical string of the endpoint conditions.
+func (e *endpoint) prepareConditions() {
+	var parts []string
+
+	if e.query != "" {
+		parts = append(parts, e.query)
+	}
+
+	sort.Slice(e.headerMatch, func(i, j int) bool {
+		if e.headerMatch[i].key != e.headerMatch[j].key {
+			return e.headerMatch[i].key < e.headerMatch[j].key
+		}
+		return e.headerMatch[i].value < e.headerMatch[j].value
+	})
+	for _, h := range e.headerMatch {
+		parts = append(parts, h.key+": "+h.value)
+	}
+
+	if len(e.accept) != 0 {
+		parts = append(parts, "Accept: "+strings.Join(e.accept, ", "))
+	}
+
+	if len(e.contentType) != 0 {
+		parts = append(parts, "Content-Type: "+strings.Join(e.contentType, ", "))
+	}
+
+	e.conditions = strings.Join(parts, " ")
+}
+
+// priority returns number of the endpoint conditions.
+func (e *endpoint) priority() int {
+	n := len(e.queryMatch) + len(e.headerMatch)
+	if len(e.accept) != 0 {
+		n++
+	}
+	if len(e.contentType) != 0 {
+		n++
+	}
+
+	return n
+}
+
+// matchHeader checks header conditions of the endpoint.
+func (e *endpoint) matchHeader(header http.Header) bool {
+	for _, h := range e.headerMatch {
+		values, ok := header[h.key]
+		if !ok {
+			return false
+		}
+
+		if h.value != "" && !containsFold(values, h.value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchContentType checks the request media type.
+func (e *endpoint) matchContentType(header http.Header) bool {
+	if len(e.contentType) == 0 {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+
+	for _, t := range e.contentType {
+		if matchMediaType(t, mediaType) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchMediaType checks media type with pattern.
+// Pattern could be a wildcard: */* or text/*
+func matchMediaType(pattern, mediaType string) bool {
+	if pattern == "*/*" || strings.EqualFold(pattern, mediaType) {
+		return true
+	}
+
+	if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+		t, _, _ := strings.Cut(mediaType, "/")
+		return strings.EqualFold(prefix, t)
+	}
+
+	return false
+}
+
+// acceptRange is a media range of the Accept header.
+type acceptRange struct {
+	mediaType string
+	q         float64
+}
+
+// parseAccept parses Accept header value.
+// Returns nil if header is not defined.
+func parseAccept(header http.Header) []acceptRange {
+	values := header.Values("Accept")
+	if len(values) == 0 {
+		return nil
+	}
+
+	ranges := []acceptRange{}
+
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil {
+				continue
+			}
+
+			q := 1.0
+			if v, ok := params["q"]; ok {
+				if f, err := strconv.ParseFloat(v, 64); err == nil && f >= 0 && f <= 1 {
+					q = f
+				}
+			}
+
+			ranges = append(ranges, acceptRange{
+				mediaType: mediaType,
+				q:         q,
+			})
+		}
+	}
+
+	return ranges
+}
+
+// quality returns quality value for the offered media type.
+// Quality is defined by the most specific media range.
+// If Accept header is not defined any type is acceptable.
+func quality(ranges []acceptRange, offer string) float64 {
+	if ranges == nil {
+		return 1
+	}
+
+	q := 0.0
+	specificity := 0
+
+	for _, r := range ranges {
+		if !matchMediaType(r.mediaType, offer) {
+			continue
+		}
+
+		s := 1
+		if r.mediaType != "*/*" {
+			s = 2
+			if !strings.HasSuffix(r.mediaType, "/*") {
+				s = 3
+			}
+		}
+
+		if s > specificity {
+			specificity = s
+			q = r.q
+		}
+	}
+
+	return q
+}
+
+// acceptQuality returns the best quality value for the endpoint types.
+func (e *endpoint) acceptQuality(ranges []acceptRange) float64 {
+	if len(e.accept) == 0 {
+		return 1
+	}
+
+	best := 0.0
+	for _, t := range e.accept {
+		if q := quality(ranges, t); q > best {
+			best = q
+		}
+	}
+
+	return best
+}
+
+// Negotiate returns the offered media type with the highest quality value
+// in the request Accept header. If several types have the same quality,
+// the first one is returned. If no type is acceptable, returns empty string.
+// If request has no Accept header, returns the first offer.
+func Negotiate(r *http.Request, offers ...string) string {
+	ranges := parseAccept(r.Header)
+
+	best := ""
+	bestQ := 0.0
+
+	for _, offer := range offers {
+		if q := quality(ranges, offer); q > bestQ {
+			best = offer
+			bestQ = q
+		}
+	}
+
+	return best
+}
+
+func notAcceptable(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, "406 not acceptable", http.StatusNotAcceptable)
+}
+
+// NotAcceptableHandler returns a simple request handler
+// that replies to each request with a “406 not acceptable” reply.
+func NotAcceptableHandler() http.Handler {
+	return http.HandlerFunc(notAcceptable)
+}
+
+func unsupportedMediaType(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, "415 unsupported media type", http.StatusUnsupportedMediaType)
+}
+
+// UnsupportedMediaTypeHandler returns a simple request handler
+// that replies to each request with a “415 unsupported media type” reply.
+func UnsupportedMediaTypeHandler() http.Handler {
+	return http.HandlerFunc(unsupportedMediaType)
+}
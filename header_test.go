@@ -0,0 +1,108 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeader_quality(t *testing.T) {
+	assert := assert.New(t)
+
+	header := http.Header{}
+	assert.Nil(parseAccept(header))
+	assert.Equal(1.0, quality(nil, "application/json"))
+
+	header.Set("Accept", "text/*;q=0.5, application/json, */*;q=0.1, text/html;q=0, bad;;")
+	ranges := parseAccept(header)
+	assert.Len(ranges, 4)
+
+	tests := []struct {
+		offer string
+		q     float64
+	}{
+		{"application/json", 1},
+		{"text/plain", 0.5},
+		{"text/html", 0},
+		{"image/png", 0.1},
+	}
+
+	for _, test := range tests {
+		assert.Equal(test.q, quality(ranges, test.offer), test.offer)
+	}
+}
+
+func TestHeader_Negotiate(t *testing.T) {
+	assert := assert.New(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	assert.Equal("application/json", Negotiate(r, "application/json", "text/plain"))
+
+	r.Header.Set("Accept", "text/plain;q=0.9, application/json;q=0.8")
+	assert.Equal("text/plain", Negotiate(r, "application/json", "text/plain"))
+
+	r.Header.Set("Accept", "image/png")
+	assert.Equal("", Negotiate(r, "application/json", "text/plain"))
+}
+
+func TestRouter_Header(t *testing.T) {
+	assert := assert.New(t)
+	router := NewRouter()
+
+	reply := func(code int) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(code)
+		}
+	}
+
+	// API versioning
+	router.Get("/api/users", reply(201), Accept("application/vnd.x.v1+json"))
+	router.Get("/api/users", reply(202), Accept("application/vnd.x.v2+json"))
+	router.Get("/api/users", reply(203), Header("X-Debug", "on"), Accept("application/vnd.x.v2+json"))
+
+	// content types
+	router.Post("/api/upload", reply(204), ContentType("application/json"))
+	router.Post("/api/upload", reply(205), ContentType("text/*"))
+
+	// header with fallback
+	router.Get("/api/info", reply(206), Header("X-Debug", ""))
+	router.Get("/api/info", reply(200))
+
+	tests := []struct {
+		method string
+		path   string
+		header map[string]string
+		code   int
+	}{
+		{http.MethodGet, "/api/users", nil, 201},
+		{http.MethodGet, "/api/users", map[string]string{"Accept": "application/vnd.x.v2+json"}, 202},
+		{http.MethodGet, "/api/users", map[string]string{"Accept": "application/vnd.x.v1+json;q=0.5, application/vnd.x.v2+json;q=0.1"}, 201},
+		{http.MethodGet, "/api/users", map[string]string{"Accept": "application/*;q=0.5, application/vnd.x.v1+json;q=0.1"}, 202},
+		{http.MethodGet, "/api/users", map[string]string{"Accept": "text/html"}, http.StatusNotAcceptable},
+		{http.MethodGet, "/api/users", map[string]string{"Accept": "application/vnd.x.v2+json", "X-Debug": "ON"}, 203},
+		{http.MethodGet, "/api/users", map[string]string{"Accept": "application/vnd.x.v1+json", "X-Debug": "on"}, 201},
+		{http.MethodPost, "/api/upload", map[string]string{"Content-Type": "application/json; charset=utf-8"}, 204},
+		{http.MethodPost, "/api/upload", map[string]string{"Content-Type": "text/csv"}, 205},
+		{http.MethodPost, "/api/upload", map[string]string{"Content-Type": "image/png"}, http.StatusUnsupportedMediaType},
+		{http.MethodPost, "/api/upload", nil, http.StatusUnsupportedMediaType},
+		{http.MethodPut, "/api/upload", nil, http.StatusMethodNotAllowed},
+		{http.MethodGet, "/api/info", map[string]string{"X-Debug": "1"}, 206},
+		{http.MethodGet, "/api/info", nil, 200},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(test.method, test.path, nil)
+		for k, v := range test.header {
+			r.Header.Set(k, v)
+		}
+		router.ServeHTTP(w, r)
+		assert.Equal(test.code, w.Code, test.method, test.path, test.header)
+	}
+
+	// variant with other conditions is not a conflict
+	assert.NoError(router.TryHandleMethod(http.MethodGet, "/api/info", testHandler(1), Header("X-Debug", "1")))
+	assert.Error(router.TryHandleMethod(http.MethodGet, "/api/info", testHandler(1), Header("x-debug", "")))
+}
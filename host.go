@@ -0,0 +1,189 @@
+package router
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+type hostWildcard struct {
+	// suffix is a host pattern without leading "*": .example.com
+	suffix string
+	router *Router
+}
+
+// hostTable is a list of virtual hosts.
+// Same as table it is never modified after publishing.
+type hostTable struct {
+	exact map[string]*Router
+	// wildcards sorted by suffix length, the longest first.
+	wildcards []hostWildcard
+}
+
+// normalizeHost removes port and trailing dot from the host
+// and converts it to lower case.
+func normalizeHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	host = strings.TrimSuffix(host, ".")
+	return strings.ToLower(host)
+}
+
+// parseHost checks host pattern and returns normalized pattern.
+// Pattern could be an exact host: example.com
+// or a wildcard for any subdomain: *.example.com
+func parseHost(pattern string) (string, bool) {
+	pattern = normalizeHost(pattern)
+
+	if pattern == "" {
+		return "", false
+	}
+
+	if strings.HasPrefix(pattern, "*.") {
+		if len(pattern) == 2 || strings.Contains(pattern[1:], "*") {
+			return "", false
+		}
+	} else if strings.Contains(pattern, "*") {
+		return "", false
+	}
+
+	return pattern, true
+}
+
+// get returns router for the host pattern.
+func (h *hostTable) get(pattern string) *Router {
+	if h == nil {
+		return nil
+	}
+
+	if strings.HasPrefix(pattern, "*") {
+		for _, w := range h.wildcards {
+			if w.suffix == pattern[1:] {
+				return w.router
+			}
+		}
+		return nil
+	}
+
+	return h.exact[pattern]
+}
+
+// with returns a copy of the host table with router for the pattern.
+// Host table could be nil.
+func (h *hostTable) with(pattern string, router *Router) *hostTable {
+	next := &hostTable{
+		exact: make(map[string]*Router),
+	}
+
+	if h != nil {
+		for k, v := range h.exact {
+			next.exact[k] = v
+		}
+		next.wildcards = append(next.wildcards, h.wildcards...)
+	}
+
+	if strings.HasPrefix(pattern, "*") {
+		next.wildcards = append(next.wildcards, hostWildcard{
+			suffix: pattern[1:],
+			router: router,
+		})
+		sort.SliceStable(next.wildcards, func(i, j int) bool {
+			return len(next.wildcards[i].suffix) > len(next.wildcards[j].suffix)
+		})
+	} else {
+		next.exact[pattern] = router
+	}
+
+	return next
+}
+
+// without returns a copy of the host table without the pattern.
+// Returns nil if table has no more hosts.
+func (h *hostTable) without(pattern string) *hostTable {
+	next := &hostTable{
+		exact: make(map[string]*Router),
+	}
+
+	for k, v := range h.exact {
+		if k != pattern {
+			next.exact[k] = v
+		}
+	}
+
+	for _, w := range h.wildcards {
+		if "*"+w.suffix != pattern {
+			next.wildcards = append(next.wildcards, w)
+		}
+	}
+
+	if len(next.exact) == 0 && len(next.wildcards) == 0 {
+		return nil
+	}
+
+	return next
+}
+
+// lookup returns router for the request host.
+// Exact host has priority over wildcards.
+// Returns nil if host is not defined.
+func (h *hostTable) lookup(host string) *Router {
+	host = normalizeHost(host)
+
+	if router, ok := h.exact[host]; ok {
+		return router
+	}
+
+	for _, w := range h.wildcards {
+		if len(host) > len(w.suffix) && strings.HasSuffix(host, w.suffix) {
+			return w.router
+		}
+	}
+
+	return nil
+}
+
+// Host returns the router for requests with the given host.
+// Router is created on the first call with the same pattern.
+// Pattern could be an exact host: api.example.com
+// or a wildcard for any subdomain: *.example.com
+// Port in the request host is ignored, host is case-insensitive.
+// Exact host has priority over wildcards, longer wildcard has priority
+// over shorter one. Requests for not defined hosts are served by r.
+//
+// Host router has own routes, middleware, and NotFoundHandler,
+// middleware of r is not applied to the host router.
+// Panics if pattern is not valid.
+func (r *Router) Host(pattern string) *Router {
+	host, ok := parseHost(pattern)
+	if !ok {
+		panic(fmt.Sprintf("router: invalid host pattern %q", pattern))
+	}
+
+	var router *Router
+
+	r.update(func(t *table) {
+		if router = t.hosts.get(host); router == nil {
+			router = NewRouter()
+			t.hosts = t.hosts.with(host, router)
+		}
+	})
+
+	return router
+}
+
+// RemoveHost removes the router for the given host pattern.
+func (r *Router) RemoveHost(pattern string) {
+	host, ok := parseHost(pattern)
+	if !ok {
+		return
+	}
+
+	r.update(func(t *table) {
+		if t.hosts.get(host) != nil {
+			t.hosts = t.hosts.without(host)
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHost_parseHost(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		pattern string
+		want    string
+		ok      bool
+	}{
+		{"example.com", "example.com", true},
+		{"Example.COM:8080", "example.com", true},
+		{"example.com.", "example.com", true},
+		{"*.example.com", "*.example.com", true},
+		{"[::1]:80", "::1", true},
+		{"", "", false},
+		{"*", "", false},
+		{"*.", "", false},
+		{"a*.example.com", "", false},
+		{"*.*.example.com", "", false},
+	}
+
+	for _, test := range tests {
+		host, ok := parseHost(test.pattern)
+		if !assert.Equal(test.ok, ok, test.pattern) {
+			return
+		}
+		assert.Equal(test.want, host, test.pattern)
+	}
+}
+
+func TestRouter_Host(t *testing.T) {
+	assert := assert.New(t)
+	router := NewRouter()
+
+	handler := func(name string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(name))
+		})
+	}
+
+	router.Handle("/", handler("default"))
+	router.Host("api.example.com").Handle("/", handler("api"))
+	router.Host("*.example.com").Handle("/", handler("wildcard"))
+	router.Host("*.eu.example.com").Handle("/", handler("eu"))
+
+	notFound := router.Host("static.example.com")
+	notFound.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	// same router for the same pattern
+	assert.Same(router.Host("API.example.com"), router.Host("api.example.com:443"))
+
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"api.example.com", "api"},
+		{"API.Example.com:8080", "api"},
+		{"api.example.com.", "api"},
+		{"www.example.com", "wildcard"},
+		{"a.b.example.com", "wildcard"},
+		{"www.eu.example.com", "eu"},
+		{"example.com", "default"},
+		{"other.org", "default"},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Host = test.host
+		router.ServeHTTP(w, r)
+
+		if !assert.Equal(test.want, w.Body.String(), test.host) {
+			return
+		}
+	}
+
+	// host has own NotFoundHandler
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Host = "static.example.com"
+	router.ServeHTTP(w, r)
+	assert.Equal(http.StatusTeapot, w.Code)
+
+	router.RemoveHost("*.example.com")
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Host = "www.example.com"
+	router.ServeHTTP(w, r)
+	assert.Equal("default", w.Body.String())
+
+	assert.Panics(func() {
+		router.Host("*")
+	})
+}
@@ -0,0 +1,57 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// mount is a handler for the mounted router.
+type mount struct {
+	router *Router
+}
+
+// ServeHTTP passes request to the mounted router.
+// Request path is relative to the mount point.
+func (m *mount) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	match, ok := MatchFrom(r)
+	if !ok {
+		// mount is called outside of the router
+		m.router.NotFoundHandler.ServeHTTP(w, r)
+		return
+	}
+
+	// match of this router is hidden from the mounted router
+	r2 := r.WithContext(&matchContext{Context: r.Context()})
+	r2.Pattern = ""
+	r2.URL = new(url.URL)
+	*r2.URL = *r.URL
+	r2.URL.Path = "/" + match.Tail
+	r2.URL.RawPath = ""
+
+	m.router.ServeHTTP(w, r2)
+}
+
+// Mount attaches the router to the prefix.
+// Prefix should be a directory path: /api/v1/
+// Mounted router receives requests with path relative to the prefix,
+// for example /api/v1/users is served by mounted router as /users.
+// Middleware and NotFoundHandler of the mounted router are applied
+// only for requests inside the prefix.
+// Panics if prefix is not a directory path.
+func (r *Router) Mount(prefix string, router *Router) {
+	r.Handle(prefix, newMount(prefix, router))
+}
+
+// newMount makes handler for the mounted router.
+// Panics if prefix is not a directory path.
+func newMount(prefix string, router *Router) *mount {
+	if !strings.HasSuffix(prefix, "/") {
+		panic(fmt.Sprintf("router: mount prefix %q should end with slash", prefix))
+	}
+
+	return &mount{
+		router: router,
+	}
+}
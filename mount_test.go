@@ -0,0 +1,121 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouter_Mount(t *testing.T) {
+	assert := assert.New(t)
+
+	api := NewRouter()
+	api.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Api", "1")
+			next.ServeHTTP(w, r)
+		})
+	})
+	api.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	api.Get("/users", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("users " + r.URL.Path))
+	})
+	api.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("user " + Param(r, "id")))
+	})
+
+	root := NewRouter()
+	root.Handle("/", testHandler(1))
+	root.Mount("/api/v1/", api)
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+		header string
+	}{
+		{"/api/v1/users", http.StatusOK, "users /users", "1"},
+		{"/api/v1/users/42", http.StatusOK, "user 42", "1"},
+		{"/api/v1/unknown", http.StatusTeapot, "", ""},
+		{"/api/v1", http.StatusNoContent, "", ""},
+		{"/users", http.StatusNoContent, "", ""},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, test.path, nil)
+		root.ServeHTTP(w, r)
+
+		if !assert.Equal(test.status, w.Code, test.path) {
+			return
+		}
+		assert.Equal(test.body, w.Body.String(), test.path)
+		assert.Equal(test.header, w.Header().Get("X-Api"), test.path)
+	}
+
+	var routes []string
+	for route := range root.Routes() {
+		routes = append(routes, route.Method+" "+route.Pattern)
+	}
+	assert.Equal(
+		[]string{" /", "GET /api/v1/users", "GET /api/v1/users/{id}"},
+		routes,
+	)
+
+	assert.Panics(func() {
+		root.Mount("/api/v2", api)
+	})
+}
+
+func TestRouter_Mount_rewrite(t *testing.T) {
+	assert := assert.New(t)
+
+	api := NewRouter()
+	api.Get("/users", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("users " + r.URL.Path))
+	})
+
+	root := NewRouter()
+	root.Use(func(next http.Handler) http.Handler {
+		return http.StripPrefix("/x", next)
+	})
+	root.Mount("/x/api/", api)
+
+	w := httptest.NewRecorder()
+	root.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/x/api/users", nil))
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Equal("users /users", w.Body.String())
+
+	// mount outside of the router
+	w = httptest.NewRecorder()
+	newMount("/api/", api).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/users", nil))
+	assert.Equal(http.StatusNotFound, w.Code)
+}
+
+func TestRouter_Mount_match(t *testing.T) {
+	assert := assert.New(t)
+
+	var match RouteMatch
+	var meta Meta
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		match, _ = MatchFrom(r)
+		meta = MetaFrom(r)
+	}
+
+	api := NewRouter()
+	api.Get("/api/", handler)
+
+	root := NewRouter()
+	root.Handle("/api/", newMount("/api/", api), Meta{"scope": "api"})
+
+	// match and metadata of the root router are not visible
+	// in the static route of the mounted router
+	w := httptest.NewRecorder()
+	root.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/api/", nil))
+	assert.Equal(RouteMatch{Pattern: "/api/", Exact: true}, match)
+	assert.Nil(meta)
+}
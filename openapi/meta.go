@@ -0,0 +1,108 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/cesbo/go-router"
+)
+
+// Keys of the route metadata.
+// Parameters and responses have own key for each item,
+// so options could be merged by router.
+const (
+	metaSummary     = "openapi.summary"
+	metaDescription = "openapi.description"
+	metaOperationID = "openapi.operationId"
+	metaTags        = "openapi.tags"
+	metaDeprecated  = "openapi.deprecated"
+	metaMethods     = "openapi.methods"
+	metaBody        = "openapi.body"
+	metaParam       = "openapi.param."
+	metaResponse    = "openapi.response."
+)
+
+// Summary returns route option with a short summary of the operation.
+func Summary(summary string) router.Meta {
+	return router.Meta{metaSummary: summary}
+}
+
+// Description returns route option with a description of the operation.
+func Description(description string) router.Meta {
+	return router.Meta{metaDescription: description}
+}
+
+// OperationID returns route option with a unique operation identifier.
+func OperationID(id string) router.Meta {
+	return router.Meta{metaOperationID: id}
+}
+
+// Tags returns route option with tags to group operations.
+func Tags(tags ...string) router.Meta {
+	return router.Meta{metaTags: tags}
+}
+
+// Deprecated returns route option to mark operation as deprecated.
+func Deprecated() router.Meta {
+	return router.Meta{metaDeprecated: true}
+}
+
+// Methods returns route option with methods to describe the route
+// registered for any method with Handle or HandleFunc.
+// By default such route is described as GET operation.
+// Methods without arguments excludes the route from the document.
+func Methods(methods ...string) router.Meta {
+	return router.Meta{metaMethods: methods}
+}
+
+// Param returns route option with the operation parameter.
+// Path parameters are defined from the route pattern,
+// option could be used to set description and schema.
+func Param(p *Parameter) router.Meta {
+	return router.Meta{metaParam + p.In + "." + p.Name: p}
+}
+
+// Query returns route option with the optional query parameter.
+// Schema is defined by the type of v, for example 0 for integer value.
+func Query(name string, v any, description string) router.Meta {
+	return Param(&Parameter{
+		Name:        name,
+		In:          "query",
+		Description: description,
+		Schema:      &Schema{Type: typeOf(v)},
+	})
+}
+
+// Body returns route option with the JSON request body.
+// Schema is defined by the type of v, for example User{} or []User{}.
+func Body(v any) router.Meta {
+	return router.Meta{metaBody: reflect.TypeOf(v)}
+}
+
+// Returns returns route option with the response for the status code.
+// Schema of the JSON body is defined by the type of v.
+// If v is nil, response has no body.
+func Returns(code int, v any) router.Meta {
+	return router.Meta{metaResponse + strconv.Itoa(code): reflect.TypeOf(v)}
+}
+
+// typeOf returns schema type for the simple value.
+func typeOf(v any) string {
+	if v == nil {
+		return "string"
+	}
+
+	return schemaType(reflect.TypeOf(v))
+}
+
+// statusText returns description for the status code.
+func statusText(code string) string {
+	if n, err := strconv.Atoi(code); err == nil {
+		if text := http.StatusText(n); text != "" {
+			return text
+		}
+	}
+
+	return "Response"
+}
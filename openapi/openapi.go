@@ -0,0 +1,332 @@
+// Package openapi makes OpenAPI 3.1 document from the router routes.
+//
+// Routes are described with metadata options:
+//
+//	r.Get("/api/users/{id}", showUser,
+//	    openapi.Summary("Get user"),
+//	    openapi.Tags("users"),
+//	    openapi.Query("fields", "", "Comma-separated list of fields"),
+//	    openapi.Returns(http.StatusOK, User{}),
+//	)
+//
+//	r.Handle("/openapi.json", openapi.Handler(r, openapi.Info{
+//	    Title:   "API",
+//	    Version: "1.0",
+//	}))
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/cesbo/go-router"
+	"gopkg.in/yaml.v3"
+)
+
+// Version is the OpenAPI specification version of the document.
+const Version = "3.1.0"
+
+// Document is an OpenAPI document.
+type Document struct {
+	OpenAPI    string               `json:"openapi" yaml:"openapi"`
+	Info       Info                 `json:"info" yaml:"info"`
+	Paths      map[string]*PathItem `json:"paths" yaml:"paths"`
+	Components *Components          `json:"components,omitempty" yaml:"components,omitempty"`
+}
+
+// Info is a metadata about the API.
+type Info struct {
+	Title       string `json:"title" yaml:"title"`
+	Version     string `json:"version" yaml:"version"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// PathItem is a list of operations for the path by lower case method.
+type PathItem map[string]*Operation
+
+// Operation describes a single API operation on a path.
+type Operation struct {
+	Summary     string               `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description string               `json:"description,omitempty" yaml:"description,omitempty"`
+	OperationID string               `json:"operationId,omitempty" yaml:"operationId,omitempty"`
+	Tags        []string             `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses" yaml:"responses"`
+	Deprecated  bool                 `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+}
+
+// Parameter describes an operation parameter.
+type Parameter struct {
+	Name string `json:"name" yaml:"name"`
+	// In is a location of the parameter: path, query, header, cookie.
+	In          string  `json:"in" yaml:"in"`
+	Description string  `json:"description,omitempty" yaml:"description,omitempty"`
+	Required    bool    `json:"required,omitempty" yaml:"required,omitempty"`
+	Schema      *Schema `json:"schema,omitempty" yaml:"schema,omitempty"`
+}
+
+// RequestBody describes a request body.
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty" yaml:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content" yaml:"content"`
+}
+
+// Response describes a response for the status code.
+type Response struct {
+	Description string                `json:"description" yaml:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty" yaml:"content,omitempty"`
+}
+
+// MediaType describes the body schema.
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty" yaml:"schema,omitempty"`
+}
+
+// Components is a set of reusable schemas.
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty" yaml:"schemas,omitempty"`
+}
+
+// New makes OpenAPI document from the router routes.
+// Route registered for any method is described as GET operation,
+// other methods could be defined with the Methods option.
+// Route registered for the method has priority over the route
+// for any method. Implicit HEAD is skipped.
+// Query conditions of the route are described as required query parameters.
+// OpenAPI has one operation for the path and method,
+// so only the first variant of the route is included.
+func New(r *router.Router, info Info) *Document {
+	d := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   make(map[string]*PathItem),
+	}
+
+	schemas := newSchemaSet()
+
+	add := func(method string, route router.Route) {
+		pattern, query, _ := strings.Cut(route.Pattern, "?")
+		path, params := parsePath(pattern)
+
+		item := d.Paths[path]
+		if item == nil {
+			item = &PathItem{}
+			d.Paths[path] = item
+		}
+
+		method = strings.ToLower(method)
+		if _, ok := (*item)[method]; ok {
+			return
+		}
+
+		op := newOperation(route.Meta, params, schemas)
+		op.Parameters = append(op.Parameters, queryConditions(query)...)
+		(*item)[method] = op
+	}
+
+	for route := range r.Routes() {
+		if route.Method != "" && route.Method != http.MethodHead {
+			add(route.Method, route)
+		}
+	}
+
+	// routes for any method
+	for route := range r.Routes() {
+		if route.Method != "" {
+			continue
+		}
+
+		methods, ok := route.Meta[metaMethods].([]string)
+		if !ok {
+			methods = []string{http.MethodGet}
+		}
+
+		for _, method := range methods {
+			add(method, route)
+		}
+	}
+
+	if len(schemas.schemas) != 0 {
+		d.Components = &Components{
+			Schemas: schemas.schemas,
+		}
+	}
+
+	return d
+}
+
+// parsePath converts route pattern to the OpenAPI path
+// and returns names of the path parameters.
+// Catch-all parameter {file...} is converted to {file}.
+func parsePath(pattern string) (string, []string) {
+	var params []string
+
+	parts := strings.Split(pattern, "/")
+	for i, part := range parts {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			name := strings.TrimSuffix(part[1:len(part)-1], "...")
+			params = append(params, name)
+			parts[i] = "{" + name + "}"
+		}
+	}
+
+	return strings.Join(parts, "/"), params
+}
+
+// queryConditions returns parameters for the route query conditions.
+func queryConditions(query string) []*Parameter {
+	if query == "" {
+		return nil
+	}
+
+	var params []*Parameter
+
+	for _, part := range strings.Split(query, "&") {
+		key, value, ok := strings.Cut(part, "=")
+		key, _ = url.QueryUnescape(key)
+
+		p := &Parameter{
+			Name:     key,
+			In:       "query",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		}
+		if ok {
+			value, _ = url.QueryUnescape(value)
+			p.Schema.Enum = []any{value}
+		}
+
+		params = append(params, p)
+	}
+
+	return params
+}
+
+// newOperation makes operation from the route metadata.
+func newOperation(meta router.Meta, params []string, schemas *schemaSet) *Operation {
+	op := &Operation{
+		Responses: make(map[string]*Response),
+	}
+
+	op.Summary, _ = meta[metaSummary].(string)
+	op.Description, _ = meta[metaDescription].(string)
+	op.OperationID, _ = meta[metaOperationID].(string)
+	op.Tags, _ = meta[metaTags].([]string)
+	op.Deprecated, _ = meta[metaDeprecated].(bool)
+
+	// path parameters in the pattern order
+	for _, name := range params {
+		p, ok := meta[metaParam+"path."+name].(*Parameter)
+		if ok {
+			c := *p
+			p = &c
+		} else {
+			p = &Parameter{
+				Name:   name,
+				In:     "path",
+				Schema: &Schema{Type: "string"},
+			}
+		}
+		p.Required = true
+		op.Parameters = append(op.Parameters, p)
+	}
+
+	// other parameters sorted by location and name
+	var keys []string
+	for key := range meta {
+		if strings.HasPrefix(key, metaParam) && !strings.HasPrefix(key, metaParam+"path.") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if p, ok := meta[key].(*Parameter); ok {
+			op.Parameters = append(op.Parameters, p)
+		}
+	}
+
+	if t, ok := meta[metaBody].(reflect.Type); ok && t != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  jsonContent(t, schemas),
+		}
+	}
+
+	for key, value := range meta {
+		code, ok := strings.CutPrefix(key, metaResponse)
+		if !ok {
+			continue
+		}
+
+		response := &Response{
+			Description: statusText(code),
+		}
+		if t, ok := value.(reflect.Type); ok && t != nil {
+			response.Content = jsonContent(t, schemas)
+		}
+		op.Responses[code] = response
+	}
+
+	if len(op.Responses) == 0 {
+		op.Responses["default"] = &Response{
+			Description: "Response",
+		}
+	}
+
+	return op
+}
+
+func jsonContent(t reflect.Type, schemas *schemaSet) map[string]*MediaType {
+	return map[string]*MediaType{
+		"application/json": {
+			Schema: schemaOf(t, schemas),
+		},
+	}
+}
+
+// JSON returns document in JSON format.
+func (d *Document) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// YAML returns document in YAML format.
+func (d *Document) YAML() ([]byte, error) {
+	return yaml.Marshal(d)
+}
+
+// Handler returns a handler that serves OpenAPI document for the router.
+// Document is made on each request, so it has actual routes.
+// Document is in YAML format if request path ends with .yaml or .yml,
+// otherwise in JSON format.
+func Handler(r *router.Router, info Info) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		d := New(r, info)
+
+		var (
+			data        []byte
+			err         error
+			contentType string
+		)
+
+		if strings.HasSuffix(req.URL.Path, ".yaml") || strings.HasSuffix(req.URL.Path, ".yml") {
+			data, err = d.YAML()
+			contentType = "application/yaml"
+		} else {
+			data, err = d.JSON()
+			contentType = "application/json"
+		}
+
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Write(data)
+	})
+}
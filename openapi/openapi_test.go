@@ -0,0 +1,226 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cesbo/go-router"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+type Group struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name" doc:"Group name"`
+}
+
+type User struct {
+	ID      int64     `json:"id"`
+	Name    string    `json:"name"`
+	Email   string    `json:"email,omitempty"`
+	Created time.Time `json:"created"`
+	Groups  []Group   `json:"groups"`
+	Parent  *User     `json:"parent"`
+	Labels  map[string]string
+	secret  string
+	Skip    string `json:"-"`
+}
+
+func handler(w http.ResponseWriter, r *http.Request) {}
+
+func newRouter() *router.Router {
+	r := router.NewRouter()
+
+	r.Get("/api/users/{id}", handler,
+		Summary("Get user"),
+		Tags("users"),
+		OperationID("user.show"),
+		Query("fields", "", "List of fields"),
+		Query("limit", 0, "Limit"),
+		Returns(http.StatusOK, User{}),
+		Returns(http.StatusNotFound, nil),
+	)
+	r.Post("/api/users", handler,
+		Summary("Create user"),
+		Body(User{}),
+		Returns(http.StatusCreated, User{}),
+	)
+	r.Delete("/api/users/{id}", handler, Deprecated())
+	r.Get("/static/{file...}", handler)
+	r.Post("/api/stream?action=start", handler, Summary("Start stream"))
+	r.Post("/api/stream?action=stop", handler, Summary("Stop stream"))
+
+	// routes for any method
+	r.HandleFunc("/api/users", handler, Summary("List users"))
+	r.HandleFunc("/api/upload", handler, Methods(http.MethodPut, http.MethodPost))
+	r.Handle("/", http.NotFoundHandler(), Methods())
+
+	return r
+}
+
+func TestOpenAPI_New(t *testing.T) {
+	assert := assert.New(t)
+
+	d := New(newRouter(), Info{Title: "API", Version: "1.0"})
+	assert.Equal(Version, d.OpenAPI)
+	assert.Equal("API", d.Info.Title)
+	assert.Len(d.Paths, 5)
+
+	show := (*d.Paths["/api/users/{id}"])["get"]
+	if assert.NotNil(show) {
+		assert.Equal("Get user", show.Summary)
+		assert.Equal("user.show", show.OperationID)
+		assert.Equal([]string{"users"}, show.Tags)
+		assert.Equal(
+			[]*Parameter{
+				{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "string"}},
+				{Name: "fields", In: "query", Description: "List of fields", Schema: &Schema{Type: "string"}},
+				{Name: "limit", In: "query", Description: "Limit", Schema: &Schema{Type: "integer"}},
+			},
+			show.Parameters,
+		)
+		assert.Equal("#/components/schemas/User", show.Responses["200"].Content["application/json"].Schema.Ref)
+		assert.Equal("Not Found", show.Responses["404"].Description)
+		assert.Nil(show.Responses["404"].Content)
+	}
+
+	create := (*d.Paths["/api/users"])["post"]
+	if assert.NotNil(create) {
+		assert.True(create.RequestBody.Required)
+		assert.Equal("Created", create.Responses["201"].Description)
+	}
+
+	// route for any method
+	list := (*d.Paths["/api/users"])["get"]
+	if assert.NotNil(list) {
+		assert.Equal("List users", list.Summary)
+	}
+	assert.Len(*d.Paths["/api/users"], 2)
+
+	upload := d.Paths["/api/upload"]
+	if assert.NotNil(upload) {
+		assert.Contains(*upload, "put")
+		assert.Contains(*upload, "post")
+		assert.NotContains(*upload, "get")
+	}
+	assert.NotContains(d.Paths, "/")
+
+	remove := (*d.Paths["/api/users/{id}"])["delete"]
+	if assert.NotNil(remove) {
+		assert.True(remove.Deprecated)
+		assert.Contains(remove.Responses, "default")
+	}
+
+	static := (*d.Paths["/static/{file}"])["get"]
+	if assert.NotNil(static) {
+		assert.Equal("file", static.Parameters[0].Name)
+	}
+
+	// first variant of the route
+	stream := (*d.Paths["/api/stream"])["post"]
+	if assert.NotNil(stream) {
+		assert.Equal("Start stream", stream.Summary)
+		assert.Equal(
+			[]*Parameter{
+				{Name: "action", In: "query", Required: true, Schema: &Schema{Type: "string", Enum: []any{"start"}}},
+			},
+			stream.Parameters,
+		)
+	}
+
+	// schemas
+	user := d.Components.Schemas["User"]
+	if assert.NotNil(user) {
+		assert.Equal("object", user.Type)
+		assert.Equal([]string{"id", "name", "created", "groups", "Labels"}, user.Required)
+		assert.Equal(&Schema{Type: "integer", Format: "int64"}, user.Properties["id"])
+		assert.Equal(&Schema{Type: "string", Format: "date-time"}, user.Properties["created"])
+		assert.Equal("#/components/schemas/User", user.Properties["parent"].Ref)
+		assert.Equal("#/components/schemas/Group", user.Properties["groups"].Items.Ref)
+		assert.Equal(&Schema{Type: "string"}, user.Properties["Labels"].AdditionalProperties)
+		assert.NotContains(user.Properties, "secret")
+		assert.NotContains(user.Properties, "Skip")
+	}
+
+	group := d.Components.Schemas["Group"]
+	if assert.NotNil(group) {
+		assert.Equal("Group name", group.Properties["name"].Description)
+	}
+}
+
+type Page[T any] struct {
+	Items []T `json:"items"`
+}
+
+func TestOpenAPI_New_schemaNames(t *testing.T) {
+	assert := assert.New(t)
+
+	// same name as the package level type
+	type User struct {
+		Login string `json:"login"`
+	}
+
+	r := router.NewRouter()
+	r.Get("/users", handler, Returns(http.StatusOK, Page[Group]{}))
+	r.Get("/accounts", handler, Returns(http.StatusOK, User{}))
+	r.Get("/profile", handler, Returns(http.StatusOK, outerUser()))
+
+	d := New(r, Info{Title: "API", Version: "1.0"})
+
+	ref := func(path string) string {
+		return (*d.Paths[path])["get"].Responses["200"].Content["application/json"].Schema.Ref
+	}
+
+	assert.Equal("#/components/schemas/Page_openapi.Group", ref("/users"))
+	assert.Equal("#/components/schemas/User", ref("/accounts"))
+	assert.Equal("#/components/schemas/openapi.User", ref("/profile"))
+
+	page := d.Components.Schemas["Page_openapi.Group"]
+	if assert.NotNil(page) {
+		assert.Equal("#/components/schemas/Group", page.Properties["items"].Items.Ref)
+	}
+
+	login := d.Components.Schemas["User"]
+	if assert.NotNil(login) {
+		assert.Contains(login.Properties, "login")
+	}
+
+	for name := range d.Components.Schemas {
+		assert.Regexp(`^[a-zA-Z0-9._-]+$`, name)
+	}
+}
+
+// outerUser returns value of the package level User type.
+func outerUser() any { return User{} }
+
+func TestOpenAPI_Handler(t *testing.T) {
+	assert := assert.New(t)
+
+	r := newRouter()
+	h := Handler(r, Info{Title: "API", Version: "1.0"})
+	r.Get("/openapi.json", h.ServeHTTP)
+	r.Get("/openapi.yaml", h.ServeHTTP)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	assert.Equal("application/json", w.Header().Get("Content-Type"))
+
+	var doc map[string]any
+	if assert.NoError(json.Unmarshal(w.Body.Bytes(), &doc)) {
+		assert.Equal("3.1.0", doc["openapi"])
+		assert.Contains(doc["paths"], "/openapi.json")
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.yaml", nil))
+	assert.Equal("application/yaml", w.Header().Get("Content-Type"))
+
+	doc = nil
+	if assert.NoError(yaml.Unmarshal(w.Body.Bytes(), &doc)) {
+		assert.Equal("3.1.0", doc["openapi"])
+		assert.Contains(doc["paths"], "/api/users/{id}")
+	}
+}
@@ -0,0 +1,209 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Schema is a JSON Schema of the value.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty" yaml:"type,omitempty"`
+	Format               string             `json:"format,omitempty" yaml:"format,omitempty"`
+	Enum                 []any              `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Description          string             `json:"description,omitempty" yaml:"description,omitempty"`
+	Items                *Schema            `json:"items,omitempty" yaml:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty" yaml:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// schemaSet is a set of named schemas for the document components.
+type schemaSet struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newSchemaSet() *schemaSet {
+	return &schemaSet{
+		schemas: make(map[string]*Schema),
+		names:   make(map[reflect.Type]string),
+	}
+}
+
+var (
+	// package path in the type arguments of generic type name
+	typePackage = regexp.MustCompile(`[^\[\],*]*/`)
+	// characters not allowed in the component name
+	invalidName = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+)
+
+// sanitizeName replaces characters not allowed in the component name.
+func sanitizeName(name string) string {
+	return strings.Trim(invalidName.ReplaceAllString(name, "_"), "_")
+}
+
+// name makes component name for the named type.
+// Type name is qualified with the package if name is used by another type.
+// Type arguments of generic type are included to the name,
+// for example Page[example.com/api.User] is named Page_api.User.
+func (s *schemaSet) name(t reflect.Type) string {
+	base := sanitizeName(typePackage.ReplaceAllString(t.Name(), ""))
+	name := base
+	if _, ok := s.schemas[name]; ok {
+		pkg := t.PkgPath()
+		name = sanitizeName(pkg[strings.LastIndex(pkg, "/")+1:]) + "." + base
+		if _, ok := s.schemas[name]; ok {
+			name = sanitizeName(strings.ReplaceAll(pkg, "/", ".")) + "." + base
+		}
+	}
+
+	s.names[t] = name
+	return name
+}
+
+// schemaType returns JSON Schema type for the simple Go type.
+func schemaType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	default:
+		return "string"
+	}
+}
+
+// schemaOf returns schema for the Go type.
+// Named structs are added to the schemas and referenced with $ref.
+func schemaOf(t reflect.Type, schemas *schemaSet) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType):
+		// custom JSON encoding
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return &Schema{}
+
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: schemaOf(t.Elem(), schemas)}
+
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaOf(t.Elem(), schemas)}
+
+	case reflect.Struct:
+		if t.Name() == "" {
+			return structSchema(t, schemas)
+		}
+
+		if name, ok := schemas.names[t]; ok {
+			return &Schema{Ref: "#/components/schemas/" + name}
+		}
+
+		name := schemas.name(t)
+		// placeholder for recursive types
+		schemas.schemas[name] = nil
+		schemas.schemas[name] = structSchema(t, schemas)
+		return &Schema{Ref: "#/components/schemas/" + name}
+
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+
+	case reflect.Int32, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	}
+
+	return &Schema{Type: schemaType(t)}
+}
+
+// structSchema returns object schema with properties
+// named same as encoding/json does.
+func structSchema(t reflect.Type, schemas *schemaSet) *Schema {
+	s := &Schema{
+		Type:       "object",
+		Properties: make(map[string]*Schema),
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name := f.Name
+		omitempty := false
+		if tag, ok := f.Tag.Lookup("json"); ok {
+			if tag == "-" {
+				continue
+			}
+
+			opts := strings.Split(tag, ",")
+			if opts[0] != "" {
+				name = opts[0]
+			}
+			for _, opt := range opts[1:] {
+				if opt == "omitempty" || opt == "omitzero" {
+					omitempty = true
+				}
+			}
+		} else if f.Anonymous {
+			// fields of the embedded struct
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded := structSchema(ft, schemas)
+				for k, v := range embedded.Properties {
+					s.Properties[k] = v
+				}
+				s.Required = append(s.Required, embedded.Required...)
+				continue
+			}
+		}
+
+		p := schemaOf(f.Type, schemas)
+		if doc := f.Tag.Get("doc"); doc != "" && p.Ref == "" {
+			p.Description = doc
+		}
+		s.Properties[name] = p
+
+		if !omitempty && f.Type.Kind() != reflect.Pointer {
+			s.Required = append(s.Required, name)
+		}
+	}
+
+	return s
+}
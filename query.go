@@ -0,0 +1,104 @@
+package router
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// queryMatcher is a condition for the query parameter.
+type queryMatcher struct {
+	key   string
+	value string
+	// presence is true if parameter should be defined with any value.
+	presence bool
+}
+
+// splitQuery splits pattern on path and query conditions.
+// Pattern with query conditions: /api/stream?action=start&verbose
+func splitQuery(pattern string) (string, string) {
+	path, query, _ := strings.Cut(pattern, "?")
+	return path, query
+}
+
+// parseQuery parses query conditions.
+// Condition key=value requires parameter with the value,
+// condition with key only requires parameter with any value.
+// Returns matchers sorted by key and canonical query string.
+func parseQuery(query string) ([]queryMatcher, string, error) {
+	if query == "" {
+		return nil, "", nil
+	}
+
+	var matchers []queryMatcher
+
+	for _, part := range strings.Split(query, "&") {
+		key, value, hasValue := strings.Cut(part, "=")
+
+		var err error
+		if key, err = url.QueryUnescape(key); err != nil {
+			return nil, "", err
+		}
+		if value, err = url.QueryUnescape(value); err != nil {
+			return nil, "", err
+		}
+
+		if key == "" {
+			return nil, "", fmt.Errorf("empty query parameter in %q", part)
+		}
+
+		for _, m := range matchers {
+			if m.key == key {
+				return nil, "", fmt.Errorf("duplicate query parameter %q", key)
+			}
+		}
+
+		matchers = append(matchers, queryMatcher{
+			key:      key,
+			value:    value,
+			presence: !hasValue,
+		})
+	}
+
+	sort.Slice(matchers, func(i, j int) bool {
+		return matchers[i].key < matchers[j].key
+	})
+
+	parts := make([]string, len(matchers))
+	for i, m := range matchers {
+		parts[i] = url.QueryEscape(m.key)
+		if !m.presence {
+			parts[i] += "=" + url.QueryEscape(m.value)
+		}
+	}
+
+	return matchers, strings.Join(parts, "&"), nil
+}
+
+// mustParseQuery parses query conditions of the pattern and panics on error.
+func mustParseQuery(pattern, query string) ([]queryMatcher, string) {
+	matchers, canonical, err := parseQuery(query)
+	if err != nil {
+		panic(fmt.Sprintf("router: invalid pattern %q: %s", pattern, err))
+	}
+
+	return matchers, canonical
+}
+
+// matchQuery checks query conditions of the endpoint.
+func (e *endpoint) matchQuery(query url.Values) bool {
+	for _, m := range e.queryMatch {
+		values, ok := query[m.key]
+		if !ok {
+			return false
+		}
+
+		if !m.presence && !slices.Contains(values, m.value) {
+			return false
+		}
+	}
+
+	return true
+}
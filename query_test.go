@@ -0,0 +1,143 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuery_parseQuery(t *testing.T) {
+	assert := assert.New(t)
+
+	matchers, query, err := parseQuery("verbose&action=start")
+	if assert.NoError(err) {
+		assert.Equal("action=start&verbose", query)
+		assert.Equal(
+			[]queryMatcher{
+				{key: "action", value: "start"},
+				{key: "verbose", presence: true},
+			},
+			matchers,
+		)
+	}
+
+	_, query, err = parseQuery("q=a+b&empty=")
+	if assert.NoError(err) {
+		assert.Equal("empty=&q=a+b", query)
+	}
+
+	for _, test := range []string{"=1", "a&a=1", "a=%zz"} {
+		_, _, err := parseQuery(test)
+		assert.Error(err, test)
+	}
+
+	assert.Panics(func() {
+		NewRouter().Handle("/api?=1", testHandler(1))
+	})
+}
+
+func TestRouter_Query(t *testing.T) {
+	assert := assert.New(t)
+	router := NewRouter()
+
+	router.Post("/api/stream?action=start", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(201)
+	})
+	router.Post("/api/stream?action=start&verbose", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(202)
+	})
+	router.Post("/api/stream?action=stop", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(203)
+	})
+	router.Get("/api/stream?id", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(206)
+	})
+	router.Get("/api/stream", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+	})
+	router.Handle("/api/session?action=close", testHandler(1))
+
+	tests := []struct {
+		method string
+		path   string
+		code   int
+	}{
+		{http.MethodPost, "/api/stream?action=start", 201},
+		{http.MethodPost, "/api/stream?verbose=1&action=start", 202},
+		{http.MethodPost, "/api/stream?action=stop&verbose", 203},
+		{http.MethodPost, "/api/stream?action=pause", http.StatusNotFound},
+		{http.MethodPost, "/api/stream", http.StatusNotFound},
+		{http.MethodGet, "/api/stream?id=1", 206},
+		{http.MethodHead, "/api/stream?id=1", 206},
+		{http.MethodGet, "/api/stream?action=start", 200},
+		{http.MethodPut, "/api/stream?action=start", http.StatusMethodNotAllowed},
+		{http.MethodPut, "/api/session?action=close", http.StatusNoContent},
+		{http.MethodPut, "/api/session", http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(test.method, test.path, nil)
+		router.ServeHTTP(w, r)
+		assert.Equal(test.code, w.Code, test.method+" "+test.path)
+	}
+
+	// introspection
+	var patterns []string
+	for route := range router.Routes() {
+		patterns = append(patterns, route.Method+" "+route.Pattern)
+	}
+	assert.Equal(
+		[]string{
+			" /api/session?action=close",
+			"GET /api/stream",
+			"GET /api/stream?id",
+			"POST /api/stream?action=start&verbose",
+			"POST /api/stream?action=start",
+			"POST /api/stream?action=stop",
+		},
+		patterns,
+	)
+
+	// remove variant
+	router.Remove("/api/stream?action=start")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/stream?action=start", nil))
+	assert.Equal(http.StatusNotFound, w.Code)
+
+	router.RemoveMethod(http.MethodGet, "/api/stream?id")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/stream?id=1", nil))
+	assert.Equal(200, w.Code)
+
+	// conflicts
+	assert.Error(router.TryHandleMethod(http.MethodPost, "/api/stream?action=stop", testHandler(2)))
+	assert.NoError(router.TryHandleMethod(http.MethodPost, "/api/stream?action=start", testHandler(2)))
+}
+
+func TestRouter_URL_query(t *testing.T) {
+	assert := assert.New(t)
+	router := NewRouter()
+
+	h := func(w http.ResponseWriter, r *http.Request) {}
+	router.Post("/api/streams/{id}?action=start&verbose", h, Name("stream.start"))
+	router.Post("/api/streams/{id}?action=stop", h, Name("stream.stop"))
+
+	url, err := router.URL("stream.start", "id", "1")
+	if assert.NoError(err) {
+		assert.Equal("/api/streams/1?action=start&verbose=", url)
+	}
+
+	url, err = router.URL("stream.stop", "id", "1", "action", "other", "force", "1")
+	if assert.NoError(err) {
+		assert.Equal("/api/streams/1?action=stop&force=1", url)
+	}
+
+	router.Remove("/api/streams/{id}?action=start&verbose")
+	_, err = router.URL("stream.start", "id", "1")
+	assert.Error(err)
+	_, err = router.URL("stream.stop", "id", "1")
+	assert.NoError(err)
+}
@@ -1,8 +1,11 @@
 package router
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"iter"
+	"strings"
 )
 
 // strcmp compares two strings and returns number of equal characters.
@@ -19,18 +22,9 @@ func strcmp(a, b string) int {
 	return eq
 }
 
-type radixEdge struct {
+type radixEdge[T any] struct {
 	prefix string
-	node   *Radix
-}
-
-func newEdge(prefix string, value any) *radixEdge {
-	return &radixEdge{
-		prefix: prefix,
-		node: &Radix{
-			value: value,
-		},
-	}
+	node   *Radix[T]
 }
 
 // split splits edge on two parts.
@@ -39,98 +33,438 @@ func newEdge(prefix string, value any) *radixEdge {
 // so we need to split edge on two parts:
 // 1. e.prefix becomes "com"
 // 2. e.node moved to the new child edge with prefix "puter"
-func (e *radixEdge) split(l int) {
-	n := &radixEdge{
+func (e *radixEdge[T]) split(l int) {
+	n := radixEdge[T]{
 		prefix: e.prefix[l:],
 		node:   e.node,
 	}
 	e.prefix = e.prefix[:l]
-	e.node = new(Radix)
+	e.node = new(Radix[T])
 	e.node.edges = append(e.node.edges, n)
 }
 
+// radixPattern is a key with path parameters.
+type radixPattern struct {
+	key    string
+	params []string
+	// catchAll is true if the last parameter captures the path tail.
+	catchAll bool
+}
+
+// path returns the key stored in the tree.
+// Catch-all parameter is not stored, it is a directory key.
+func (p *radixPattern) path() string {
+	if p.catchAll {
+		return p.key[:strings.LastIndexByte(p.key, '{')]
+	}
+
+	return p.key
+}
+
+// parsePattern parses path parameters in the pattern.
+// Parameter is a whole path segment with name in braces: /streams/{id}/sessions
+// Pattern may end with catch-all parameter: /static/{file...}
+// Returns nil if pattern has no parameters.
+func parsePattern(pattern string) (*radixPattern, error) {
+	var p *radixPattern
+
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '{':
+			if i > 0 && pattern[i-1] != '/' {
+				return nil, errors.New("parameter should start a segment")
+			}
+
+			l := strings.IndexByte(pattern[i:], '}')
+			if l == -1 {
+				return nil, errors.New("missing closing brace")
+			}
+
+			name := pattern[i+1 : i+l]
+			catchAll := strings.HasSuffix(name, "...")
+			if catchAll {
+				name = name[:len(name)-3]
+			}
+			if name == "" || strings.ContainsAny(name, "{/.") {
+				return nil, fmt.Errorf("invalid parameter name %q", name)
+			}
+
+			i += l
+			if catchAll && i+1 != len(pattern) {
+				return nil, errors.New("catch-all parameter should be the last segment")
+			}
+			if i+1 < len(pattern) && pattern[i+1] != '/' {
+				return nil, errors.New("parameter should end a segment")
+			}
+
+			if p == nil {
+				p = &radixPattern{key: pattern}
+			}
+			p.catchAll = catchAll
+			for _, v := range p.params {
+				if v == name {
+					return nil, fmt.Errorf("duplicate parameter name %q", name)
+				}
+			}
+			p.params = append(p.params, name)
+
+		case '}':
+			return nil, errors.New("unexpected closing brace")
+		}
+	}
+
+	return p, nil
+}
+
+// mustParsePattern parses pattern and panics on error.
+func mustParsePattern(pattern string) *radixPattern {
+	p, err := parsePattern(pattern)
+	if err != nil {
+		panic(fmt.Sprintf("router: invalid pattern %q: %s", pattern, err))
+	}
+
+	return p
+}
+
 // Radix is a radix tree implementation.
-type Radix struct {
-	edges []*radixEdge
-	value any
+// T is a type of values stored in the tree.
+//
+// Keys inserted with InsertPattern may have path parameters.
+// Parameter matches a whole path segment: /streams/{id}/sessions.
+// Static edges have priority over parameters.
+// Catch-all parameter at the end of the pattern: /static/{file...}
+// makes a directory key /static/ and captures the path tail.
+type Radix[T any] struct {
+	// edges sorted by the first character of the prefix.
+	// Child edges always have different first characters.
+	edges []radixEdge[T]
+	// param is a child node for the path parameter segment.
+	param *Radix[T]
+	// pattern is defined for values inserted with path parameters.
+	pattern  *radixPattern
+	value    T
+	hasValue bool
+}
+
+// PathParam is a path parameter captured by the path lookup.
+type PathParam struct {
+	Key   string
+	Value string
+}
+
+// AnyRadix is a radix tree with untyped values.
+// It keeps compatibility with code written for the non-generic tree.
+type AnyRadix = Radix[any]
+
+// isNil checks if value is a nil interface.
+func isNil[T any](value T) bool {
+	return any(value) == nil
 }
 
 // find finds the edge that has the longest prefix match with the key.
 // It returns the edge index, edge, and the number of equal characters.
-func (n *Radix) find(key string) (int, *radixEdge, int) {
-	for i := 0; i < len(n.edges); i++ {
-		e := n.edges[i]
-		eq := strcmp(e.prefix, key)
-		if eq != 0 {
-			return i, e, eq
+// Key should not be empty.
+// If edge not found it returns index where the new edge should be inserted.
+func (n *Radix[T]) find(key string) (int, *radixEdge[T], int) {
+	c := key[0]
+
+	// binary search by the first character
+	i, j := 0, len(n.edges)
+	for i < j {
+		h := int(uint(i+j) >> 1)
+		if n.edges[h].prefix[0] < c {
+			i = h + 1
+		} else {
+			j = h
 		}
 	}
 
-	return -1, nil, 0
+	if i < len(n.edges) && n.edges[i].prefix[0] == c {
+		e := &n.edges[i]
+		return i, e, strcmp(e.prefix, key)
+	}
+
+	return i, nil, 0
+}
+
+// insertEdge inserts edge at the given position keeping edges order.
+func (n *Radix[T]) insertEdge(i int, e radixEdge[T]) {
+	n.edges = append(n.edges, radixEdge[T]{})
+	copy(n.edges[i+1:], n.edges[i:])
+	n.edges[i] = e
+}
+
+// removeEdge removes edge at the given position keeping edges order.
+func (n *Radix[T]) removeEdge(i int) {
+	copy(n.edges[i:], n.edges[i+1:])
+	n.edges[len(n.edges)-1] = radixEdge[T]{}
+	n.edges = n.edges[:len(n.edges)-1]
+}
+
+// clone returns a copy of the node with its own edges list.
+// Child nodes are shared with the original node.
+func (n *Radix[T]) clone() *Radix[T] {
+	c := *n
+	c.edges = append([]radixEdge[T](nil), n.edges...)
+	return &c
 }
 
 // Insert inserts a new value into the tree.
-// If value is nil it removes the given key.
+// If value is a nil interface it removes the given key.
 // Returns the old value and true if value was overwritten.
-func (n *Radix) Insert(key string, value any) (any, bool) {
-	if value == nil {
+func (n *Radix[T]) Insert(key string, value T) (T, bool) {
+	if isNil(value) {
 		return n.Remove(key)
 	}
 
+	_, old, ok := n.insert(key, value, nil, false)
+	return old, ok
+}
+
+// InsertPattern inserts a new value into the tree same as Insert.
+// Pattern may have path parameters. Parameter names are not significant
+// for the tree, so /a/{id} and /a/{name} is a same key.
+// Panics if pattern is not valid.
+func (n *Radix[T]) InsertPattern(pattern string, value T) (T, bool) {
+	if isNil(value) {
+		return n.RemovePattern(pattern)
+	}
+
+	_, old, ok := n.insertPattern(pattern, value, false)
+	return old, ok
+}
+
+// insertPattern parses pattern and inserts value into the tree.
+func (n *Radix[T]) insertPattern(pattern string, value T, cow bool) (*Radix[T], T, bool) {
+	p := mustParsePattern(pattern)
+	if p != nil {
+		pattern = p.path()
+	}
+
+	return n.insert(pattern, value, p, cow)
+}
+
+// insert inserts a new value into the tree.
+// Path parameters in the key are parsed only if p is defined.
+// If cow is true the tree is not modified, all nodes on the path
+// to the key are copied and the new root is returned.
+// Returns the root node, the old value and true if value was overwritten.
+func (n *Radix[T]) insert(key string, value T, p *radixPattern, cow bool) (*Radix[T], T, bool) {
+	var zero T
+
+	if cow {
+		n = n.clone()
+	}
+
 	if key == "" {
-		if n.value == nil {
+		n.pattern = p
+
+		if !n.hasValue {
 			n.value = value
-			return nil, false
+			n.hasValue = true
+			return n, zero, false
 		} else {
 			n.value, value = value, n.value
-			return value, true
+			return n, value, true
 		}
 	}
 
-	_, e, eq := n.find(key)
+	static := key
+	if p != nil {
+		if key[0] == '{' {
+			// path parameter
+			l := strings.IndexByte(key, '}') + 1
+			param := n.param
+			if param == nil {
+				param = new(Radix[T])
+			}
+
+			node, old, ok := param.insert(key[l:], value, p, cow)
+			n.param = node
+			return n, old, ok
+		}
+
+		if l := strings.IndexByte(key, '{'); l != -1 {
+			static = key[:l]
+		}
+	}
+
+	i, e, eq := n.find(static)
 
 	switch eq {
 	case 0:
 		// Edge not found
-		n.edges = append(
-			n.edges,
-			newEdge(key, value),
-		)
+		node, _, _ := new(Radix[T]).insert(key[len(static):], value, p, false)
+		n.insertEdge(i, radixEdge[T]{prefix: static, node: node})
 
 	case len(e.prefix):
 		// The prefix is shorter than the edge prefix
-		return e.node.Insert(key[eq:], value)
+		node, old, ok := e.node.insert(key[eq:], value, p, cow)
+		e.node = node
+		return n, old, ok
 
-	case len(key):
+	case len(static):
 		// The key is shorter than the prefix
 		e.split(eq)
-		e.node.value = value
+		e.node, _, _ = e.node.insert(key[eq:], value, p, false)
 
 	default:
 		// The prefix has a mismatch with the key
 		e.split(eq)
-		e.node.edges = append(
-			e.node.edges,
-			newEdge(key[eq:], value),
-		)
+		node, _, _ := new(Radix[T]).insert(key[len(static):], value, p, false)
+		i, _, _ = e.node.find(static[eq:])
+		e.node.insertEdge(i, radixEdge[T]{prefix: static[eq:], node: node})
 	}
 
-	return nil, false
+	return n, zero, false
 }
 
 // Lookup finds the value for the given key.
-func (n *Radix) Lookup(key string) (any, bool) {
+func (n *Radix[T]) Lookup(key string) (T, bool) {
 	for key != "" {
 		_, e, l := n.find(key)
 		if e == nil || l != len(e.prefix) {
-			return nil, false
+			var zero T
+			return zero, false
 		}
 
 		n = e.node
 		key = key[l:]
 	}
 
-	return n.value, true
+	return n.value, n.hasValue
+}
+
+// LookupPattern finds the value inserted with InsertPattern.
+func (n *Radix[T]) LookupPattern(pattern string) (T, bool) {
+	if n = n.lookupPattern(pattern); n != nil {
+		return n.value, n.hasValue
+	}
+
+	var zero T
+	return zero, false
+}
+
+// registeredPattern returns the pattern inserted for the node of the pattern.
+// Patterns of the same node could differ in parameter names:
+// /users/{id} and /users/{uid}, or /static/ and /static/{file...}
+func (n *Radix[T]) registeredPattern(pattern string) (string, bool) {
+	n = n.lookupPattern(pattern)
+	if n == nil || !n.hasValue {
+		return "", false
+	}
+
+	if n.pattern != nil {
+		return n.pattern.key, true
+	}
+
+	// static pattern is a key of the node
+	if p, _ := parsePattern(pattern); p != nil {
+		return p.path(), true
+	}
+
+	return pattern, true
+}
+
+// lookupPattern returns the node for the pattern.
+// Parameter names are not compared.
+func (n *Radix[T]) lookupPattern(pattern string) *Radix[T] {
+	p, err := parsePattern(pattern)
+	if err != nil {
+		return nil
+	}
+
+	key := pattern
+	if p != nil {
+		key = p.path()
+	}
+
+	for key != "" {
+		if p != nil && key[0] == '{' {
+			if n.param == nil {
+				return nil
+			}
+
+			n = n.param
+			key = key[strings.IndexByte(key, '}')+1:]
+			continue
+		}
+
+		_, e, l := n.find(key)
+		if e == nil || l != len(e.prefix) {
+			return nil
+		}
+
+		n = e.node
+		key = key[l:]
+	}
+
+	return n
+}
+
+// walkPattern calls fn for each value with key that is a prefix of the pattern
+// at the segment boundary: key /admin is a prefix of /admin and /admin/users,
+// but not of /administrator. Keys are visited from the shortest one.
+func (n *Radix[T]) walkPattern(pattern string, fn func(value T)) {
+	p, err := parsePattern(pattern)
+	if err != nil {
+		return
+	}
+
+	key := pattern
+	if p != nil {
+		key = p.path()
+	}
+
+	// last is a last byte of the visited key
+	last := byte('/')
+
+	for {
+		if n.hasValue && (last == '/' || key == "" || key[0] == '/') {
+			fn(n.value)
+		}
+
+		if key == "" {
+			return
+		}
+
+		if p != nil && key[0] == '{' {
+			if n.param == nil {
+				return
+			}
+
+			n = n.param
+			key = key[strings.IndexByte(key, '}')+1:]
+			last = '}'
+			continue
+		}
+
+		_, e, l := n.find(key)
+		if e == nil || l != len(e.prefix) {
+			return
+		}
+
+		n = e.node
+		last = key[l-1]
+		key = key[l:]
+	}
+}
+
+// Match is a result of the path lookup.
+type Match[T any] struct {
+	// Key is the matched key or pattern.
+	Key string
+	// Value is the value stored with the key.
+	Value T
+	// Exact is true if the key is equal to the path
+	// and false if the path matched with the directory key.
+	Exact bool
+	// Tail is a part of the path after the matched key.
+	// It is empty on exact match.
+	Tail string
+	// Params is a list of path parameters.
+	// It is nil if the key has no parameters.
+	Params []PathParam
 }
 
 // LookupPath finds the value for the given path.
@@ -151,84 +485,402 @@ func (n *Radix) Lookup(key string) (any, bool) {
 // next request will be handled by 404 user not found:
 // - `/api/users/`
 // - `/api/users/not-found`
-func (n *Radix) LookupPath(path string) any {
-	lastRoot := n
+//
+// If nothing is found it returns zero value.
+func (n *Radix[T]) LookupPath(path string) T {
+	m, _ := n.MatchPath(path)
+	return m.Value
+}
 
-	for path != "" {
-		_, e, l := n.find(path)
-		if e == nil || l != len(e.prefix) {
-			return lastRoot.value
+// radixSearch is a state of the path lookup.
+type radixSearch[T any] struct {
+	path string
+	// values of the path parameters
+	values []string
+
+	// the longest directory match
+	root       *Radix[T]
+	rootLen    int
+	rootValues []string
+}
+
+// MatchPath finds the value for the given path same as LookupPath.
+// It returns details about the matched key.
+// If nothing is found it returns false.
+func (n *Radix[T]) MatchPath(path string) (Match[T], bool) {
+	s := radixSearch[T]{
+		path: path,
+		root: n,
+	}
+
+	if node := n.search(&s, 0); node != nil {
+		return node.match(path, len(path), s.values)
+	}
+
+	return s.root.match(path, s.rootLen, s.rootValues)
+}
+
+// search finds the node with the key equal to the path[pos:].
+// Static edges are checked first, then the path parameter.
+func (n *Radix[T]) search(s *radixSearch[T], pos int) *Radix[T] {
+	path := s.path
+
+	for pos < len(path) {
+		_, e, l := n.find(path[pos:])
+		if e != nil && l == len(e.prefix) {
+			next := pos + l
+			if e.node.hasValue && (e.prefix[l-1] == '/') && next > s.rootLen {
+				s.root = e.node
+				s.rootLen = next
+				s.rootValues = nil
+				if len(s.values) != 0 {
+					// values might be overwritten on the next search step
+					s.rootValues = append([]string(nil), s.values...)
+				}
+			}
+
+			if n.param == nil {
+				n = e.node
+				pos = next
+				continue
+			}
+
+			if node := e.node.search(s, next); node != nil {
+				return node
+			}
 		}
 
-		if e.node.value != nil && (e.prefix[l-1] == '/') {
-			lastRoot = e.node
+		if n.param == nil {
+			return nil
 		}
 
-		n = e.node
-		path = path[l:]
+		// path parameter matches the whole segment
+		l = strings.IndexByte(path[pos:], '/')
+		if l == -1 {
+			l = len(path) - pos
+		}
+		if l == 0 {
+			return nil
+		}
+
+		values := s.values
+		s.values = append(s.values, path[pos:pos+l])
+		if node := n.param.search(s, pos+l); node != nil {
+			return node
+		}
+		s.values = values
+
+		return nil
 	}
 
-	return n.value
+	if n.hasValue {
+		return n
+	}
+
+	return nil
+}
+
+// match returns match for the node with key path[:l].
+func (n *Radix[T]) match(path string, l int, values []string) (Match[T], bool) {
+	if !n.hasValue {
+		return Match[T]{}, false
+	}
+
+	m := Match[T]{
+		Key:   path[:l],
+		Value: n.value,
+		Exact: l == len(path),
+		Tail:  path[l:],
+	}
+
+	if p := n.pattern; p != nil {
+		m.Key = p.key
+		m.Params = make([]PathParam, len(p.params))
+		for i, name := range p.params {
+			m.Params[i].Key = name
+			if i < len(values) {
+				m.Params[i].Value = values[i]
+			}
+		}
+
+		if p.catchAll {
+			m.Params[len(p.params)-1].Value = m.Tail
+		}
+	}
+
+	return m, true
+}
+
+// lookupFold finds the key equal to the path ignoring case.
+// Parameter values and catch-all tail are kept as is.
+// Returns buf with the key appended.
+func (n *Radix[T]) lookupFold(path string, buf []byte) ([]byte, bool) {
+	if path == "" {
+		return buf, n.hasValue
+	}
+
+	for i := range n.edges {
+		e := &n.edges[i]
+		l := len(e.prefix)
+		if len(path) >= l && strings.EqualFold(path[:l], e.prefix) {
+			if b, ok := e.node.lookupFold(path[l:], append(buf, e.prefix...)); ok {
+				return b, true
+			}
+		}
+	}
+
+	if n.param != nil {
+		l := strings.IndexByte(path, '/')
+		if l == -1 {
+			l = len(path)
+		}
+		if l != 0 {
+			if b, ok := n.param.lookupFold(path[l:], append(buf, path[:l]...)); ok {
+				return b, true
+			}
+		}
+	}
+
+	if n.hasValue && n.pattern != nil && n.pattern.catchAll {
+		return append(buf, path...), true
+	}
+
+	return buf, false
 }
 
 // Remove removes the value for the given path.
 // Returns value and true if value was found and removed.
-func (n *Radix) Remove(key string) (any, bool) {
+func (n *Radix[T]) Remove(key string) (T, bool) {
+	_, value, ok := n.remove(key, false, false)
+	return value, ok
+}
+
+// RemovePattern removes the value inserted with InsertPattern.
+// Returns value and true if value was found and removed.
+func (n *Radix[T]) RemovePattern(pattern string) (T, bool) {
+	_, value, ok := n.removePattern(pattern, false)
+	return value, ok
+}
+
+// removePattern parses pattern and removes value from the tree.
+func (n *Radix[T]) removePattern(pattern string, cow bool) (*Radix[T], T, bool) {
+	p, err := parsePattern(pattern)
+	if err != nil {
+		var zero T
+		return n, zero, false
+	}
+
+	if p != nil {
+		pattern = p.path()
+	}
+
+	return n.remove(pattern, true, cow)
+}
+
+// remove removes the value for the given path.
+// Path parameters in the key are parsed only if pattern is true.
+// If cow is true the tree is not modified, all nodes on the path
+// to the key are copied and the new root is returned.
+// Returns the root node, value and true if value was found and removed.
+func (n *Radix[T]) remove(key string, pattern bool, cow bool) (*Radix[T], T, bool) {
+	var zero T
+
 	if key == "" {
 		// node found. unset value and return
-		if n.value == nil {
+		if !n.hasValue {
 			// root node without value
-			return nil, false
+			return n, zero, false
+		}
+
+		if cow {
+			n = n.clone()
 		}
 
 		value := n.value
-		n.value = nil
-		return value, true
+		n.value = zero
+		n.hasValue = false
+		n.pattern = nil
+		return n, value, true
+	}
+
+	if pattern && key[0] == '{' {
+		// path parameter
+		if n.param == nil {
+			return n, zero, false
+		}
+
+		l := strings.IndexByte(key, '}') + 1
+		node, value, ok := n.param.remove(key[l:], pattern, cow)
+		if !ok {
+			return n, zero, false
+		}
+
+		if cow {
+			n = n.clone()
+		}
+
+		if node.hasValue || node.param != nil || len(node.edges) != 0 {
+			n.param = node
+		} else {
+			n.param = nil
+		}
+
+		return n, value, true
 	}
 
 	i, e, l := n.find(key)
 	if e == nil || l != len(e.prefix) {
 		// node not found
-		return nil, false
+		return n, zero, false
 	}
 
-	value, ok := e.node.Remove(key[l:])
+	node, value, ok := e.node.remove(key[l:], pattern, cow)
+	if !ok {
+		return n, zero, false
+	}
 
-	if e.node.value == nil {
-		switch len(e.node.edges) {
+	if cow {
+		n = n.clone()
+		e = &n.edges[i]
+	}
+	e.node = node
+
+	if !node.hasValue && node.param == nil {
+		switch len(node.edges) {
 		case 0:
 			// remove empty node
-			last := len(n.edges) - 1
-			n.edges[i] = n.edges[last]
-			n.edges = n.edges[:last]
+			n.removeEdge(i)
 		case 1:
 			// merge nodes
-			last := e.node.edges[0]
+			last := node.edges[0]
 			e.prefix += last.prefix
 			e.node = last.node
 		}
 	}
 
-	return value, ok
+	return n, value, true
+}
+
+// Walk calls fn for each value in the tree.
+// Keys are visited in lexicographic order,
+// keys with path parameters after static keys on the same level.
+// If fn returns false, the iteration stops.
+func (n *Radix[T]) Walk(fn func(key string, value T) bool) {
+	n.walk(nil, fn)
+}
+
+// WalkPrefix calls fn for each value with key that starts with prefix.
+// Keys are visited in lexicographic order.
+// Prefix is compared with static part of the keys only.
+// If fn returns false, the iteration stops.
+func (n *Radix[T]) WalkPrefix(prefix string, fn func(key string, value T) bool) {
+	var key []byte
+
+	for prefix != "" {
+		_, e, l := n.find(prefix)
+		if e == nil {
+			return
+		}
+
+		if l == len(prefix) {
+			// prefix ends on this edge, whole subtree is matched
+			e.node.walk(append(key, e.prefix...), fn)
+			return
+		}
+
+		if l != len(e.prefix) {
+			return
+		}
+
+		key = append(key, e.prefix...)
+		n = e.node
+		prefix = prefix[l:]
+	}
+
+	n.walk(key, fn)
+}
+
+// walk visits node and its children.
+// key is a full key of the node, buffer is reused by children.
+// Returns false if iteration was stopped.
+func (n *Radix[T]) walk(key []byte, fn func(key string, value T) bool) bool {
+	if n.hasValue {
+		k := string(key)
+		if n.pattern != nil {
+			k = n.pattern.key
+		}
+
+		if !fn(k, n.value) {
+			return false
+		}
+	}
+
+	for i := range n.edges {
+		e := &n.edges[i]
+		if !e.node.walk(append(key, e.prefix...), fn) {
+			return false
+		}
+	}
+
+	if n.param != nil {
+		// full key is defined in the pattern
+		return n.param.walk(append(key, "{}"...), fn)
+	}
+
+	return true
 }
 
-func (n *Radix) dump(out io.Writer, pad string) {
-	last := len(n.edges) - 1
+// All returns an iterator over all keys and values in the tree.
+// Keys are visited in lexicographic order.
+func (n *Radix[T]) All() iter.Seq2[string, T] {
+	return func(yield func(string, T) bool) {
+		n.Walk(yield)
+	}
+}
+
+// Prefix returns an iterator over keys and values
+// with key that starts with prefix.
+// Keys are visited in lexicographic order.
+func (n *Radix[T]) Prefix(prefix string) iter.Seq2[string, T] {
+	return func(yield func(string, T) bool) {
+		n.WalkPrefix(prefix, yield)
+	}
+}
+
+// dumpValue returns node value or nil if node has no value.
+func (n *Radix[T]) dumpValue() any {
+	if !n.hasValue {
+		return nil
+	}
+
+	return n.value
+}
+
+func (n *Radix[T]) dump(out io.Writer, pad string) {
+	edges := n.edges
+	if n.param != nil {
+		edges = append(edges[:len(edges):len(edges)], radixEdge[T]{
+			prefix: "{}",
+			node:   n.param,
+		})
+	}
+
+	last := len(edges) - 1
 
 	for i := 0; i <= last; i++ {
-		e := n.edges[i]
+		e := edges[i]
 
 		if i != last {
-			fmt.Fprintf(out, "%s├─── %s -> %v\n", pad, e.prefix, e.node.value)
+			fmt.Fprintf(out, "%s├─── %s -> %v\n", pad, e.prefix, e.node.dumpValue())
 			e.node.dump(out, (pad + "│    "))
 		} else {
-			fmt.Fprintf(out, "%s└─── %s -> %v\n", pad, e.prefix, e.node.value)
+			fmt.Fprintf(out, "%s└─── %s -> %v\n", pad, e.prefix, e.node.dumpValue())
 			e.node.dump(out, (pad + "     "))
 		}
 	}
 }
 
 // Dump prints the tree to out.
-func (n *Radix) Dump(out io.Writer) {
+func (n *Radix[T]) Dump(out io.Writer) {
 	n.dump(out, "")
 }
@@ -3,16 +3,17 @@ package router
 import (
 	"os"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
 // Edge returns edge with given prefix.
-func (n *Radix) edge(prefix string) *radixEdge {
-	for _, e := range n.edges {
-		if e.prefix == prefix {
-			return e
+func (n *Radix[T]) edge(prefix string) *radixEdge[T] {
+	for i := range n.edges {
+		if n.edges[i].prefix == prefix {
+			return &n.edges[i]
 		}
 	}
 
@@ -43,11 +44,17 @@ func TestRadix_strcmp(t *testing.T) {
 func TestRadix_split(t *testing.T) {
 	assert := assert.New(t)
 
-	edge := newEdge("computer", 1)
+	edge := radixEdge[int]{
+		prefix: "computer",
+		node: &Radix[int]{
+			value:    1,
+			hasValue: true,
+		},
+	}
 	edge.split(3)
 
 	assert.Equal("com", edge.prefix)
-	assert.Nil(edge.node.value)
+	assert.False(edge.node.hasValue)
 	assert.Len(edge.node.edges, 1)
 
 	edge = edge.node.edges[0]
@@ -73,7 +80,7 @@ func TestRadix_Insert(t *testing.T) {
 		//     └── ic
 		//         ├── on (6)
 		//         └── undus (7)
-		currentNode := new(Radix)
+		currentNode := new(Radix[int])
 		currentNode.Insert("romane", 1)
 		currentNode.Insert("romanus", 2)
 		currentNode.Insert("romulus", 3)
@@ -85,7 +92,7 @@ func TestRadix_Insert(t *testing.T) {
 
 		currentNode.Dump(os.Stdout)
 
-		assert.Nil(currentNode.value)
+		assert.False(currentNode.hasValue)
 		assert.Len(currentNode.edges, 1)
 
 		// level 1
@@ -93,7 +100,7 @@ func TestRadix_Insert(t *testing.T) {
 		if !assert.NotNil(r) {
 			return
 		}
-		assert.Nil(r.node.value)
+		assert.False(r.node.hasValue)
 		assert.Len(r.node.edges, 2)
 
 		// level 2
@@ -108,7 +115,7 @@ func TestRadix_Insert(t *testing.T) {
 		if !assert.NotNil(rub) {
 			return
 		}
-		assert.Nil(rub.node.value)
+		assert.False(rub.node.hasValue)
 		assert.Len(rub.node.edges, 2)
 
 		// level 3
@@ -121,21 +128,21 @@ func TestRadix_Insert(t *testing.T) {
 		if !assert.NotNil(roman) {
 			return
 		}
-		assert.Nil(roman.node.value)
+		assert.False(roman.node.hasValue)
 		assert.Len(roman.node.edges, 2)
 
 		rube := rub.node.edge("e")
 		if !assert.NotNil(rube) {
 			return
 		}
-		assert.Nil(rube.node.value)
+		assert.False(rube.node.hasValue)
 		assert.Len(rube.node.edges, 2)
 
 		rubic := rub.node.edge("ic")
 		if !assert.NotNil(rubic) {
 			return
 		}
-		assert.Nil(rubic.node.value)
+		assert.False(rubic.node.hasValue)
 		assert.Len(rubic.node.edges, 2)
 
 		// level 4
@@ -174,7 +181,7 @@ func TestRadix_Insert(t *testing.T) {
 		assert := assert.New(t)
 
 		var updated bool
-		currentNode := new(Radix)
+		currentNode := new(Radix[int])
 
 		_, updated = currentNode.Insert("alert", 1)
 		assert.False(updated)
@@ -197,10 +204,83 @@ func TestRadix_Insert(t *testing.T) {
 	})
 }
 
+func TestRadix_Lookup(t *testing.T) {
+	assert := assert.New(t)
+
+	currentNode := new(Radix[string])
+	currentNode.Insert("romane", "a")
+	currentNode.Insert("romanus", "b")
+
+	if v, ok := currentNode.Lookup("romane"); assert.True(ok) {
+		assert.Equal("a", v)
+	}
+
+	// intermediate node without value
+	_, ok := currentNode.Lookup("roman")
+	assert.False(ok)
+
+	_, ok = currentNode.Lookup("romulus")
+	assert.False(ok)
+}
+
+func TestRadix_AnyRadix(t *testing.T) {
+	assert := assert.New(t)
+
+	currentNode := new(AnyRadix)
+	currentNode.Insert("apple", 1)
+	currentNode.Insert("orange", "2")
+
+	if v, ok := currentNode.Lookup("orange"); assert.True(ok) {
+		assert.Equal("2", v)
+	}
+
+	// nil value removes the key
+	if v, ok := currentNode.Insert("apple", nil); assert.True(ok) {
+		assert.Equal(1, v)
+	}
+	_, ok := currentNode.Lookup("apple")
+	assert.False(ok)
+}
+
+func TestRadix_edgesOrder(t *testing.T) {
+	assert := assert.New(t)
+
+	isSorted := func(n *Radix[int]) bool {
+		for i := 1; i < len(n.edges); i++ {
+			if n.edges[i-1].prefix[0] >= n.edges[i].prefix[0] {
+				return false
+			}
+		}
+		return true
+	}
+
+	currentNode := new(Radix[int])
+	for i, key := range []string{"k", "b", "z", "a", "m", "c", "y"} {
+		currentNode.Insert(key, i)
+	}
+	assert.True(isSorted(currentNode))
+	assert.Len(currentNode.edges, 7)
+
+	// split should keep order in the new node
+	currentNode.Insert("mx", 10)
+	currentNode.Insert("ma", 11)
+	currentNode.Insert("mz", 12)
+	assert.True(isSorted(currentNode.edge("m").node))
+
+	currentNode.Remove("c")
+	currentNode.Remove("k")
+	assert.True(isSorted(currentNode))
+	assert.Len(currentNode.edges, 5)
+
+	for i, key := range []string{"a", "b", "m", "y", "z"} {
+		assert.Equal(key, currentNode.edges[i].prefix)
+	}
+}
+
 func TestRadix_LookupPath(t *testing.T) {
 	assert := assert.New(t)
 
-	currentNode := new(Radix)
+	currentNode := new(Radix[int])
 
 	currentNode.Insert("/", 0)
 	currentNode.Insert("/api", 1)
@@ -258,6 +338,318 @@ func TestRadix_LookupPath(t *testing.T) {
 	}
 }
 
+func TestRadix_MatchPath(t *testing.T) {
+	assert := assert.New(t)
+
+	currentNode := new(Radix[int])
+	currentNode.Insert("/", 0)
+	currentNode.Insert("/api/users", 2)
+	currentNode.Insert("/api/users/", 3)
+	currentNode.Insert("/static/a", 4)
+	currentNode.Insert("/static/b", 5)
+
+	tests := []struct {
+		path string
+		want Match[int]
+	}{
+		{"/", Match[int]{Key: "/", Value: 0, Exact: true}},
+		{"/api/users", Match[int]{Key: "/api/users", Value: 2, Exact: true}},
+		{"/api/users/", Match[int]{Key: "/api/users/", Value: 3, Exact: true}},
+		{"/api/users/admin", Match[int]{Key: "/api/users/", Value: 3, Tail: "admin"}},
+		{"/api/", Match[int]{Key: "/", Value: 0, Tail: "api/"}},
+		// intermediate node without value
+		{"/static/", Match[int]{Key: "/", Value: 0, Tail: "static/"}},
+	}
+
+	for _, test := range tests {
+		m, ok := currentNode.MatchPath(test.path)
+		if !assert.True(ok, test.path) || !assert.Equal(test.want, m, test.path) {
+			return
+		}
+	}
+
+	// without root value
+	currentNode.Remove("/")
+	_, ok := currentNode.MatchPath("/api/")
+	assert.False(ok)
+	_, ok = currentNode.MatchPath("")
+	assert.False(ok)
+}
+
+func TestRadix_parsePattern(t *testing.T) {
+	assert := assert.New(t)
+
+	p, err := parsePattern("/static/path")
+	assert.NoError(err)
+	assert.Nil(p)
+
+	p, err = parsePattern("/streams/{id}/sessions/{session}")
+	if assert.NoError(err) && assert.NotNil(p) {
+		assert.Equal([]string{"id", "session"}, p.params)
+	}
+
+	for _, pattern := range []string{
+		"/streams/x{id}",
+		"/streams/{id}x",
+		"/streams/{id",
+		"/streams/id}",
+		"/streams/{}",
+		"/streams/{a{b}",
+		"/streams/{id}/{id}",
+	} {
+		_, err := parsePattern(pattern)
+		assert.Error(err, pattern)
+	}
+}
+
+func TestRadix_Params(t *testing.T) {
+	assert := assert.New(t)
+
+	currentNode := new(Radix[int])
+	currentNode.InsertPattern("/", 0)
+	currentNode.InsertPattern("/streams/{id}", 1)
+	currentNode.InsertPattern("/streams/{id}/sessions", 2)
+	currentNode.InsertPattern("/streams/new", 3)
+	currentNode.InsertPattern("/streams/{id}/sessions/{session}", 4)
+	currentNode.InsertPattern("/users/{name}/", 5)
+	currentNode.InsertPattern("/streams/new/{name}/info", 6)
+
+	currentNode.Dump(os.Stdout)
+
+	tests := []struct {
+		path string
+		want Match[int]
+	}{
+		{"/streams/new", Match[int]{Key: "/streams/new", Value: 3, Exact: true}},
+		{
+			"/streams/42",
+			Match[int]{
+				Key:    "/streams/{id}",
+				Value:  1,
+				Exact:  true,
+				Params: []PathParam{{"id", "42"}},
+			},
+		},
+		{
+			"/streams/42/sessions",
+			Match[int]{
+				Key:    "/streams/{id}/sessions",
+				Value:  2,
+				Exact:  true,
+				Params: []PathParam{{"id", "42"}},
+			},
+		},
+		{
+			// static "new" does not have sessions, backtrack to parameter
+			"/streams/new/sessions",
+			Match[int]{
+				Key:    "/streams/{id}/sessions",
+				Value:  2,
+				Exact:  true,
+				Params: []PathParam{{"id", "new"}},
+			},
+		},
+		{
+			"/streams/new/sessions/7",
+			Match[int]{
+				Key:    "/streams/{id}/sessions/{session}",
+				Value:  4,
+				Exact:  true,
+				Params: []PathParam{{"id", "new"}, {"session", "7"}},
+			},
+		},
+		{
+			"/streams/new/x/info",
+			Match[int]{
+				Key:    "/streams/new/{name}/info",
+				Value:  6,
+				Exact:  true,
+				Params: []PathParam{{"name", "x"}},
+			},
+		},
+		{
+			// directory with parameter
+			"/users/john/avatar.png",
+			Match[int]{
+				Key:    "/users/{name}/",
+				Value:  5,
+				Tail:   "avatar.png",
+				Params: []PathParam{{"name", "john"}},
+			},
+		},
+		// empty segment does not match parameter
+		{"/streams/", Match[int]{Key: "/", Value: 0, Tail: "streams/"}},
+		{"/streams//sessions", Match[int]{Key: "/", Value: 0, Tail: "streams//sessions"}},
+		// parameter does not match multiple segments
+		{"/streams/42/x", Match[int]{Key: "/", Value: 0, Tail: "streams/42/x"}},
+	}
+
+	for _, test := range tests {
+		m, ok := currentNode.MatchPath(test.path)
+		if !assert.True(ok, test.path) || !assert.Equal(test.want, m, test.path) {
+			return
+		}
+	}
+
+	// parameter names are not significant
+	if v, updated := currentNode.InsertPattern("/streams/{name}", 7); assert.True(updated) {
+		assert.Equal(1, v)
+	}
+	if m, ok := currentNode.MatchPath("/streams/42"); assert.True(ok) {
+		assert.Equal("/streams/{name}", m.Key)
+		assert.Equal([]PathParam{{"name", "42"}}, m.Params)
+	}
+
+	var keys []string
+	currentNode.Walk(func(key string, _ int) bool {
+		keys = append(keys, key)
+		return true
+	})
+	assert.Equal(
+		[]string{
+			"/",
+			"/streams/new",
+			"/streams/new/{name}/info",
+			"/streams/{name}",
+			"/streams/{id}/sessions",
+			"/streams/{id}/sessions/{session}",
+			"/users/{name}/",
+		},
+		keys,
+	)
+
+	// remove
+	if v, ok := currentNode.RemovePattern("/streams/{x}/sessions/{y}"); assert.True(ok) {
+		assert.Equal(4, v)
+	}
+	if v, ok := currentNode.RemovePattern("/streams/new/{name}/info"); assert.True(ok) {
+		assert.Equal(6, v)
+	}
+	_, ok := currentNode.RemovePattern("/streams/{id}/x")
+	assert.False(ok)
+	_, ok = currentNode.RemovePattern("/streams/{id")
+	assert.False(ok)
+
+	if m, ok := currentNode.MatchPath("/streams/new/sessions"); assert.True(ok) {
+		assert.Equal(2, m.Value)
+	}
+
+	currentNode.RemovePattern("/streams/{id}")
+	currentNode.RemovePattern("/streams/{id}/sessions")
+	// parameter nodes are removed
+	e := currentNode.edge("/")
+	if assert.NotNil(e) {
+		assert.NotNil(e.node.edge("streams/new"))
+		assert.Nil(e.node.param)
+	}
+
+	assert.Panics(func() {
+		currentNode.InsertPattern("/streams/{id", 1)
+	})
+}
+
+func TestRadix_CatchAll(t *testing.T) {
+	assert := assert.New(t)
+
+	currentNode := new(Radix[int])
+	currentNode.InsertPattern("/", 0)
+	currentNode.InsertPattern("/static/{file...}", 1)
+	currentNode.InsertPattern("/static/index.html", 2)
+	currentNode.InsertPattern("/users/{name}/files/{path...}", 3)
+
+	tests := []struct {
+		path string
+		want Match[int]
+	}{
+		{
+			"/static/",
+			Match[int]{
+				Key:    "/static/{file...}",
+				Value:  1,
+				Exact:  true,
+				Params: []PathParam{{"file", ""}},
+			},
+		},
+		{
+			"/static/css/main.css",
+			Match[int]{
+				Key:    "/static/{file...}",
+				Value:  1,
+				Tail:   "css/main.css",
+				Params: []PathParam{{"file", "css/main.css"}},
+			},
+		},
+		{"/static/index.html", Match[int]{Key: "/static/index.html", Value: 2, Exact: true}},
+		{
+			"/users/john/files/docs/readme.txt",
+			Match[int]{
+				Key:    "/users/{name}/files/{path...}",
+				Value:  3,
+				Tail:   "docs/readme.txt",
+				Params: []PathParam{{"name", "john"}, {"path", "docs/readme.txt"}},
+			},
+		},
+		{"/static", Match[int]{Key: "/", Value: 0, Tail: "static"}},
+	}
+
+	for _, test := range tests {
+		m, ok := currentNode.MatchPath(test.path)
+		if !assert.True(ok, test.path) || !assert.Equal(test.want, m, test.path) {
+			return
+		}
+	}
+
+	// catch-all is a directory key
+	if v, ok := currentNode.Lookup("/static/"); assert.True(ok) {
+		assert.Equal(1, v)
+	}
+
+	var keys []string
+	for key := range currentNode.Prefix("/static/") {
+		keys = append(keys, key)
+	}
+	assert.Equal([]string{"/static/{file...}", "/static/index.html"}, keys)
+
+	// directory key replaces catch-all
+	if v, updated := currentNode.InsertPattern("/static/", 4); assert.True(updated) {
+		assert.Equal(1, v)
+	}
+	if m, ok := currentNode.MatchPath("/static/a"); assert.True(ok) {
+		assert.Equal("/static/", m.Key)
+		assert.Nil(m.Params)
+	}
+
+	if v, ok := currentNode.RemovePattern("/users/{name}/files/{path...}"); assert.True(ok) {
+		assert.Equal(3, v)
+	}
+
+	for _, pattern := range []string{
+		"/static/{file...}/x",
+		"/static/x{file...}",
+		"/static/{...}",
+	} {
+		_, err := parsePattern(pattern)
+		assert.Error(err, pattern)
+	}
+}
+
+func TestRadix_MatchPath_allocs(t *testing.T) {
+	assert := assert.New(t)
+
+	currentNode := new(Radix[int])
+	currentNode.InsertPattern("/", 0)
+	currentNode.InsertPattern("/api/users", 1)
+	currentNode.InsertPattern("/api/users/", 2)
+	currentNode.InsertPattern("/api/streams/{id}/info", 3)
+
+	for _, path := range []string{"/api/users", "/api/users/admin", "/not-found"} {
+		allocs := testing.AllocsPerRun(100, func() {
+			_, _ = currentNode.MatchPath(path)
+		})
+		assert.Zero(allocs, path)
+	}
+}
+
 func TestRadix_Remove(t *testing.T) {
 	t.Run("base", func(t *testing.T) {
 		assert := assert.New(t)
@@ -275,7 +667,7 @@ func TestRadix_Remove(t *testing.T) {
 		//     └── ic
 		//         ├── on (6)
 		//         └── undus (7)
-		currentNode := new(Radix)
+		currentNode := new(Radix[int])
 		currentNode.Insert("romane", 1)
 		currentNode.Insert("romanus", 2)
 		currentNode.Insert("romulus", 3)
@@ -320,7 +712,7 @@ func TestRadix_Remove(t *testing.T) {
 	t.Run("remove twice", func(t *testing.T) {
 		assert := assert.New(t)
 
-		currentNode := new(Radix)
+		currentNode := new(Radix[int])
 		currentNode.Insert("apple", 1)
 		currentNode.Insert("orange", 2)
 
@@ -337,8 +729,132 @@ func TestRadix_Remove(t *testing.T) {
 	})
 }
 
+func TestRadix_copyOnWrite(t *testing.T) {
+	assert := assert.New(t)
+
+	origin := new(Radix[int])
+	origin.Insert("romane", 1)
+	origin.Insert("romanus", 2)
+	origin.Insert("romulus", 3)
+
+	dump := func(n *Radix[int]) string {
+		var out strings.Builder
+		n.Dump(&out)
+		return out.String()
+	}
+	before := dump(origin)
+
+	// insert with split
+	next, _, updated := origin.insert("rom", 4, nil, true)
+	assert.False(updated)
+	assert.Equal(before, dump(origin))
+	if v, ok := next.Lookup("rom"); assert.True(ok) {
+		assert.Equal(4, v)
+	}
+
+	// overwrite
+	next, v, updated := next.insert("romane", 5, nil, true)
+	if assert.True(updated) {
+		assert.Equal(1, v)
+	}
+	assert.Equal(before, dump(origin))
+
+	// remove with merge
+	next, v, removed := next.remove("romulus", false, true)
+	if assert.True(removed) {
+		assert.Equal(3, v)
+	}
+	assert.Equal(before, dump(origin))
+	_, ok := next.Lookup("romulus")
+	assert.False(ok)
+
+	// remove not existing key returns the same root
+	same, _, removed := next.remove("rubens", false, true)
+	assert.False(removed)
+	assert.Same(next, same)
+
+	if v, ok := origin.Lookup("romane"); assert.True(ok) {
+		assert.Equal(1, v)
+	}
+}
+
+func TestRadix_Walk(t *testing.T) {
+	currentNode := new(Radix[int])
+	currentNode.Insert("rubicon", 6)
+	currentNode.Insert("romulus", 3)
+	currentNode.Insert("rom", 8)
+	currentNode.Insert("rubens", 4)
+	currentNode.Insert("romane", 1)
+	currentNode.Insert("ruber", 5)
+	currentNode.Insert("romanus", 2)
+
+	t.Run("all", func(t *testing.T) {
+		assert := assert.New(t)
+
+		var keys []string
+		var values []int
+		currentNode.Walk(func(key string, value int) bool {
+			keys = append(keys, key)
+			values = append(values, value)
+			return true
+		})
+
+		assert.Equal(
+			[]string{"rom", "romane", "romanus", "romulus", "rubens", "ruber", "rubicon"},
+			keys,
+		)
+		assert.Equal([]int{8, 1, 2, 3, 4, 5, 6}, values)
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		assert := assert.New(t)
+
+		var keys []string
+		for key := range currentNode.All() {
+			keys = append(keys, key)
+			if len(keys) == 2 {
+				break
+			}
+		}
+
+		assert.Equal([]string{"rom", "romane"}, keys)
+	})
+
+	t.Run("prefix", func(t *testing.T) {
+		assert := assert.New(t)
+
+		tests := []struct {
+			prefix string
+			want   []string
+		}{
+			{"", []string{"rom", "romane", "romanus", "romulus", "rubens", "ruber", "rubicon"}},
+			{"rom", []string{"rom", "romane", "romanus", "romulus"}},
+			{"roma", []string{"romane", "romanus"}},
+			{"rube", []string{"rubens", "ruber"}},
+			{"rubicon", []string{"rubicon"}},
+			{"rubicons", nil},
+			{"rx", nil},
+			{"a", nil},
+		}
+
+		for _, test := range tests {
+			var keys []string
+			for key, value := range currentNode.Prefix(test.prefix) {
+				keys = append(keys, key)
+				if v, ok := currentNode.Lookup(key); assert.True(ok) {
+					assert.Equal(v, value)
+				}
+			}
+
+			if !assert.Equal(test.want, keys, test.prefix) {
+				return
+			}
+		}
+	})
+}
+
 func BenchmarkInsert(b *testing.B) {
-	r := new(Radix)
+	r := new(Radix[bool])
 
 	var keys []string
 	for n := 0; n < b.N; n++ {
@@ -354,7 +870,7 @@ func BenchmarkInsert(b *testing.B) {
 }
 
 func BenchmarkInsertRemove(b *testing.B) {
-	r := new(Radix)
+	r := new(Radix[bool])
 
 	var keys []string
 	for n := 0; n < b.N; n++ {
@@ -369,3 +885,25 @@ func BenchmarkInsertRemove(b *testing.B) {
 		_, _ = r.Remove(keys[n])
 	}
 }
+
+func BenchmarkLookupFanout(b *testing.B) {
+	for _, fanout := range []int{4, 16, 64, 256} {
+		b.Run(strconv.Itoa(fanout), func(b *testing.B) {
+			r := new(Radix[int])
+
+			var keys []string
+			for i := 0; i < fanout; i++ {
+				key := "/api/" + string([]byte{byte(i)}) + "/item"
+				keys = append(keys, key)
+				_, _ = r.Insert(key, i)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for n := 0; n < b.N; n++ {
+				_, _ = r.Lookup(keys[n%fanout])
+			}
+		})
+	}
+}
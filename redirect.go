@@ -0,0 +1,126 @@
+package router
+
+import (
+	"net/http"
+	"path"
+	"strings"
+)
+
+// cleanPath returns the canonical path same as path.Clean,
+// but keeps the trailing slash.
+func cleanPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+
+	if p[0] != '/' {
+		p = "/" + p
+	}
+
+	np := path.Clean(p)
+	if p[len(p)-1] == '/' && np != "/" {
+		np += "/"
+	}
+
+	return np
+}
+
+// toggleSlash adds or removes the trailing slash.
+// Returns empty string for the root path.
+func toggleSlash(p string) string {
+	if p == "/" {
+		return ""
+	}
+
+	if strings.HasSuffix(p, "/") {
+		return p[:len(p)-1]
+	}
+
+	return p + "/"
+}
+
+// fixPath returns an alternative form of the request path
+// according to the router redirect policies.
+// Cleaned path or path with toggled slash of the exact match
+// has priority, cleaned path could be returned with a directory match.
+//
+// Dir is a key of the directory route matched by the request path,
+// or empty if path is not matched. Exact match of the alternative
+// is always better than the directory route. Other alternatives
+// are redirected only if served by a deeper directory,
+// for example /files/A to /files/a is not redirected with the /files/ route.
+func (r *Router) fixPath(t *table, p string, dir string) (string, bool) {
+	exact := func(alt string) bool {
+		m, ok := t.radix.MatchPath(alt)
+		return ok && m.Exact
+	}
+
+	deeper := func(alt string) bool {
+		m, ok := t.radix.MatchPath(alt)
+		if !ok {
+			return false
+		}
+
+		if dir == "" {
+			return true
+		}
+
+		return len(m.Key) > len(dir) && (!m.Exact || strings.HasSuffix(m.Key, "/"))
+	}
+
+	fixed := p
+	if r.RedirectFixedPath {
+		fixed = cleanPath(p)
+		if fixed != p && exact(fixed) {
+			return fixed, true
+		}
+	}
+
+	if r.RedirectTrailingSlash {
+		if alt := toggleSlash(fixed); alt != "" && exact(alt) {
+			return alt, true
+		}
+	}
+
+	if r.RedirectCaseInsensitive {
+		if b, ok := t.radix.lookupFold(fixed, nil); ok && deeper(string(b)) {
+			return string(b), true
+		}
+
+		if r.RedirectTrailingSlash {
+			if alt := toggleSlash(fixed); alt != "" {
+				if b, ok := t.radix.lookupFold(alt, nil); ok && deeper(string(b)) {
+					return string(b), true
+				}
+			}
+		}
+	}
+
+	if fixed != p && deeper(fixed) {
+		return fixed, true
+	}
+
+	return "", false
+}
+
+// redirect replies with redirect to the alternative form of the request path.
+// Dir is a key of the directory route matched by the request path.
+// Returns false if redirect is not possible.
+func (r *Router) redirect(t *table, w http.ResponseWriter, req *http.Request, dir string) bool {
+	p, ok := r.fixPath(t, req.URL.Path, dir)
+	if !ok || p == req.URL.Path || strings.HasPrefix(p, "//") {
+		return false
+	}
+
+	u := *req.URL
+	u.Path = p
+	u.RawPath = ""
+
+	code := http.StatusPermanentRedirect
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		code = http.StatusMovedPermanently
+	}
+
+	http.Redirect(w, req, u.RequestURI(), code)
+	return true
+}
@@ -0,0 +1,130 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedirect_cleanPath(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"", "/"},
+		{"/", "/"},
+		{"a/b", "/a/b"},
+		{"/a//b", "/a/b"},
+		{"/a/./b/", "/a/b/"},
+		{"/a/../b", "/b"},
+		{"/../a", "/a"},
+		{"/a/b/..", "/a"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(test.want, cleanPath(test.path), test.path)
+	}
+}
+
+func TestRouter_Redirect(t *testing.T) {
+	assert := assert.New(t)
+	router := NewRouter()
+
+	router.Handle("/api/users", testHandler(1))
+	router.Handle("/api/groups/", testHandler(2))
+	router.Handle("/streams/{id}", testHandler(3))
+	router.Handle("/static/{file...}", testHandler(4))
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(method, path, nil)
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	// policies are disabled by default
+	assert.Equal(http.StatusNotFound, serve(http.MethodGet, "/api/users/").Code)
+
+	router.RedirectTrailingSlash = true
+	router.RedirectFixedPath = true
+	router.RedirectCaseInsensitive = true
+
+	tests := []struct {
+		method   string
+		path     string
+		code     int
+		location string
+	}{
+		{http.MethodGet, "/api/users", http.StatusNoContent, ""},
+		{http.MethodGet, "/api/users/", http.StatusMovedPermanently, "/api/users"},
+		{http.MethodHead, "/api/users/", http.StatusMovedPermanently, "/api/users"},
+		{http.MethodPost, "/api/users/", http.StatusPermanentRedirect, "/api/users"},
+		{http.MethodGet, "/api/groups", http.StatusMovedPermanently, "/api/groups/"},
+		{http.MethodGet, "/api/users/?q=1", http.StatusMovedPermanently, "/api/users?q=1"},
+		{http.MethodGet, "/api//users", http.StatusMovedPermanently, "/api/users"},
+		{http.MethodGet, "/api/x/../users", http.StatusMovedPermanently, "/api/users"},
+		{http.MethodGet, "/api/x/../groups/a/", http.StatusMovedPermanently, "/api/groups/a/"},
+		{http.MethodGet, "/API/Users", http.StatusMovedPermanently, "/api/users"},
+		{http.MethodGet, "/API/Users/", http.StatusMovedPermanently, "/api/users"},
+		{http.MethodGet, "/Streams/ID1", http.StatusMovedPermanently, "/streams/ID1"},
+		{http.MethodGet, "/streams/1/", http.StatusMovedPermanently, "/streams/1"},
+		{http.MethodGet, "/Static/CSS/Main.css", http.StatusMovedPermanently, "/static/CSS/Main.css"},
+		{http.MethodGet, "/api/groups/a/b", http.StatusNoContent, ""},
+		{http.MethodGet, "/api/unknown", http.StatusNotFound, ""},
+	}
+
+	for _, test := range tests {
+		w := serve(test.method, test.path)
+		assert.Equal(test.code, w.Code, test.path)
+		assert.Equal(test.location, w.Header().Get("Location"), test.path)
+	}
+}
+
+func TestRouter_Redirect_directory(t *testing.T) {
+	assert := assert.New(t)
+	router := NewRouter()
+	router.RedirectTrailingSlash = true
+	router.RedirectFixedPath = true
+	router.RedirectCaseInsensitive = true
+
+	router.Handle("/", testHandler(1))
+	router.Handle("/files/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	router.Handle("/files/readme", testHandler(2))
+	router.Handle("/tree/", testHandler(3))
+	router.Handle("/api/users", testHandler(4))
+
+	tests := []struct {
+		path     string
+		code     int
+		location string
+	}{
+		// paths served by the directory are not redirected
+		{"/files/README", http.StatusAccepted, ""},
+		{"/files/a//b", http.StatusAccepted, ""},
+		{"/Files/readme", http.StatusNoContent, ""},
+		// exact match is better than the directory
+		{"/api//users", http.StatusMovedPermanently, "/api/users"},
+		{"/api/./users", http.StatusMovedPermanently, "/api/users"},
+		{"/api/users/", http.StatusMovedPermanently, "/api/users"},
+		{"/files/readme/", http.StatusMovedPermanently, "/files/readme"},
+		// alternative is served by a deeper directory
+		{"/tree", http.StatusMovedPermanently, "/tree/"},
+		{"/TREE/", http.StatusMovedPermanently, "/tree/"},
+		{"/a/../files/x", http.StatusMovedPermanently, "/files/x"},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, test.path, nil)
+		router.ServeHTTP(w, r)
+
+		assert.Equal(test.code, w.Code, test.path)
+		assert.Equal(test.location, w.Header().Get("Location"), test.path)
+	}
+}
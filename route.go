@@ -0,0 +1,418 @@
+package router
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Option configures the route on registration.
+type Option interface {
+	apply(e *endpoint)
+}
+
+// apply adds middleware to the route.
+// Route middleware is called after router and group middleware.
+func (mw MiddlewareFunc) apply(e *endpoint) {
+	e.mw = append(e.mw, mw)
+}
+
+// Meta is an arbitrary route metadata: required scopes, owner, description.
+// Metadata is an option for route registration, several Meta options are merged.
+// Matched route metadata is available in the handler and middleware with MetaFrom.
+type Meta map[string]any
+
+// apply adds metadata to the route.
+func (m Meta) apply(e *endpoint) {
+	meta := make(Meta, len(e.meta)+len(m))
+	for k, v := range e.meta {
+		meta[k] = v
+	}
+	for k, v := range m {
+		meta[k] = v
+	}
+	e.meta = meta
+}
+
+// endpoint is a handler registered for the route method.
+type endpoint struct {
+	handler http.Handler
+	mw      []MiddlewareFunc
+	// name is a route name to build URL.
+	name string
+	// source is a call site of the registration: file:line
+	source string
+	meta   Meta
+	// query is a canonical query conditions: action=start&verbose
+	query       string
+	queryMatch  []queryMatcher
+	headerMatch []headerMatcher
+	accept      []string
+	contentType []string
+	// conditions is a canonical string of all conditions
+	// to identify the route variant.
+	conditions string
+	// serve is a handler wrapped with router, group and route middleware.
+	// It is composed on registration and on middleware changes.
+	serve http.Handler
+	// expiry is a deadline of the route registered with HandleUntil.
+	expiry   *expiry
+	onExpire func(pattern string)
+}
+
+// newEndpoint makes endpoint with options.
+func newEndpoint(handler http.Handler, opts []Option) *endpoint {
+	e := &endpoint{
+		handler: handler,
+	}
+
+	for _, opt := range opts {
+		opt.apply(e)
+	}
+
+	return e
+}
+
+// route is a value stored in the radix tree for each pattern.
+// Route is never modified after publishing, changes make a new copy.
+type route struct {
+	// handlers by request method.
+	// Handler with empty method serves any method.
+	handlers map[string]*endpoint
+	// variants are handlers with query and header conditions by request method.
+	// Variants are sorted by priority: variant with more conditions first,
+	// variants with the same number of conditions are sorted by conditions.
+	variants map[string][]*endpoint
+}
+
+// copy returns a copy of the route.
+// Route could be nil.
+func (rt *route) copy() *route {
+	next := &route{
+		handlers: make(map[string]*endpoint),
+	}
+
+	if rt != nil {
+		for k, v := range rt.handlers {
+			next.handlers[k] = v
+		}
+
+		if len(rt.variants) != 0 {
+			next.variants = make(map[string][]*endpoint, len(rt.variants))
+			for k, v := range rt.variants {
+				next.variants[k] = v
+			}
+		}
+	}
+
+	return next
+}
+
+// clone returns a copy of the route with each handler replaced by fn.
+func (rt *route) clone(fn func(e *endpoint) *endpoint) *route {
+	next := rt.copy()
+
+	for k, v := range next.handlers {
+		next.handlers[k] = fn(v)
+	}
+
+	for k, v := range next.variants {
+		list := make([]*endpoint, len(v))
+		for i, e := range v {
+			list[i] = fn(e)
+		}
+		next.variants[k] = list
+	}
+
+	return next
+}
+
+// with returns a copy of the route with handler for the method.
+// Handler with conditions is added to the method variants.
+// Route could be nil.
+func (rt *route) with(method string, handler *endpoint) *route {
+	next := rt.copy()
+
+	if handler.conditions == "" {
+		next.handlers[method] = handler
+		return next
+	}
+
+	if next.variants == nil {
+		next.variants = make(map[string][]*endpoint)
+	}
+
+	list := make([]*endpoint, 0, len(next.variants[method])+1)
+	for _, v := range next.variants[method] {
+		if v.conditions != handler.conditions {
+			list = append(list, v)
+		}
+	}
+	list = append(list, handler)
+
+	sort.Slice(list, func(i, j int) bool {
+		if pi, pj := list[i].priority(), list[j].priority(); pi != pj {
+			return pi > pj
+		}
+		return list[i].conditions < list[j].conditions
+	})
+	next.variants[method] = list
+
+	return next
+}
+
+// without returns a copy of the route without handler for the method.
+// Returns nil if route has no more handlers.
+func (rt *route) without(method string) *route {
+	return rt.withoutVariant(method, "")
+}
+
+// withoutVariant returns a copy of the route without handler
+// for the method and conditions.
+// Returns nil if route has no more handlers.
+func (rt *route) withoutVariant(method, conditions string) *route {
+	next := rt.copy()
+
+	if conditions == "" {
+		delete(next.handlers, method)
+	} else {
+		var list []*endpoint
+		for _, v := range next.variants[method] {
+			if v.conditions != conditions {
+				list = append(list, v)
+			}
+		}
+
+		if len(list) != 0 {
+			next.variants[method] = list
+		} else {
+			delete(next.variants, method)
+		}
+	}
+
+	if len(next.handlers) == 0 && len(next.variants) == 0 {
+		return nil
+	}
+
+	return next
+}
+
+// lookup returns handler registered for the method and conditions.
+func (rt *route) lookup(method, conditions string) (*endpoint, bool) {
+	if conditions == "" {
+		e, ok := rt.handlers[method]
+		return e, ok
+	}
+
+	for _, e := range rt.variants[method] {
+		if e.conditions == conditions {
+			return e, true
+		}
+	}
+
+	return nil, false
+}
+
+// each calls fn for each handler of the route.
+// Handlers are ordered by method, handler for the method
+// is followed by its variants.
+func (rt *route) each(fn func(method string, e *endpoint) bool) bool {
+	methods := make([]string, 0, len(rt.handlers)+len(rt.variants))
+	for method := range rt.handlers {
+		methods = append(methods, method)
+	}
+	for method := range rt.variants {
+		if _, ok := rt.handlers[method]; !ok {
+			methods = append(methods, method)
+		}
+	}
+	sort.Strings(methods)
+
+	for _, method := range methods {
+		if e, ok := rt.handlers[method]; ok && !fn(method, e) {
+			return false
+		}
+
+		for _, e := range rt.variants[method] {
+			if !fn(method, e) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// match returns handler for the request.
+// Variants with matched conditions have priority over the handler
+// for the same method. Variants with the same priority and Accept
+// conditions are selected by the quality value of the Accept header.
+// HEAD request is served by GET handler if HEAD handler is not defined.
+//
+// If handler is not found, returns status for the reply:
+// 405 if route has no handlers for the request method,
+// 406 or 415 if variants are not matched by Accept or Content-Type,
+// 404 if variants are not matched by other conditions.
+func (rt *route) match(r *http.Request) (*endpoint, int) {
+	var (
+		query  url.Values
+		accept []acceptRange
+		parsed bool
+	)
+
+	status := http.StatusMethodNotAllowed
+
+	find := func(method string) (*endpoint, bool) {
+		variants := rt.variants[method]
+		if len(variants) != 0 {
+			if !parsed {
+				query = r.URL.Query()
+				accept = parseAccept(r.Header)
+				parsed = true
+			}
+
+			var best *endpoint
+			bestQ := 0.0
+			notAcceptable := false
+			unsupported := false
+
+			for _, e := range variants {
+				if best != nil && e.priority() < best.priority() {
+					break
+				}
+
+				if !e.matchQuery(query) || !e.matchHeader(r.Header) {
+					continue
+				}
+
+				if !e.matchContentType(r.Header) {
+					unsupported = true
+					continue
+				}
+
+				q := e.acceptQuality(accept)
+				if q == 0 {
+					notAcceptable = true
+					continue
+				}
+
+				if q > bestQ {
+					best = e
+					bestQ = q
+				}
+			}
+
+			if best != nil {
+				return best, true
+			}
+
+			switch {
+			case notAcceptable:
+				status = http.StatusNotAcceptable
+			case unsupported && status != http.StatusNotAcceptable:
+				status = http.StatusUnsupportedMediaType
+			case status == http.StatusMethodNotAllowed:
+				status = http.StatusNotFound
+			}
+		}
+
+		e, ok := rt.handlers[method]
+		return e, ok
+	}
+
+	if e, ok := find(r.Method); ok {
+		return e, 0
+	}
+
+	if r.Method == http.MethodHead {
+		if e, ok := find(http.MethodGet); ok {
+			return e, 0
+		}
+	}
+
+	if e, ok := find(""); ok {
+		return e, 0
+	}
+
+	return nil, status
+}
+
+// handler returns handler for the request method.
+// HEAD request is served by GET handler if HEAD handler is not defined.
+func (rt *route) handler(method string) (*endpoint, bool) {
+	if handler, ok := rt.handlers[method]; ok {
+		return handler, true
+	}
+
+	if method == http.MethodHead {
+		if handler, ok := rt.handlers[http.MethodGet]; ok {
+			return handler, true
+		}
+	}
+
+	handler, ok := rt.handlers[""]
+	return handler, ok
+}
+
+// methods returns sorted list of methods with defined handlers and variants.
+// Handler for any method is not included.
+// List has HEAD if GET is defined.
+func (rt *route) methods() []string {
+	methods := make([]string, 0, len(rt.handlers)+len(rt.variants)+1)
+	for method := range rt.handlers {
+		if method != "" {
+			methods = append(methods, method)
+		}
+	}
+	for method := range rt.variants {
+		if _, ok := rt.handlers[method]; !ok && method != "" {
+			methods = append(methods, method)
+		}
+	}
+
+	if slices.Contains(methods, http.MethodGet) {
+		if !slices.Contains(methods, http.MethodHead) {
+			methods = append(methods, http.MethodHead)
+		}
+	}
+
+	sort.Strings(methods)
+	return methods
+}
+
+// allow returns value for the Allow header.
+// If options is true, OPTIONS is included as it has automatic reply.
+func (rt *route) allow(options bool) string {
+	methods := rt.methods()
+
+	if options {
+		if _, ok := rt.handlers[http.MethodOptions]; !ok {
+			methods = append(methods, http.MethodOptions)
+			sort.Strings(methods)
+		}
+	}
+
+	return strings.Join(methods, ", ")
+}
+
+func methodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, "405 method not allowed", http.StatusMethodNotAllowed)
+}
+
+// MethodNotAllowedHandler returns a simple request handler
+// that replies to each request with a “405 method not allowed” reply.
+func MethodNotAllowedHandler() http.Handler {
+	return http.HandlerFunc(methodNotAllowed)
+}
+
+func defaultOptions(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// OptionsHandler returns a request handler for automatic OPTIONS reply.
+// It replies with “204 No Content”, Allow header is set by Router.
+func OptionsHandler() http.Handler {
+	return http.HandlerFunc(defaultOptions)
+}
@@ -0,0 +1,266 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoute_methods(t *testing.T) {
+	assert := assert.New(t)
+
+	var rt *route
+	rt = rt.with(http.MethodPost, &endpoint{handler: testHandler(1)})
+	rt = rt.with(http.MethodGet, &endpoint{handler: testHandler(2)})
+	rt = rt.with(http.MethodDelete, &endpoint{handler: testHandler(3)})
+
+	assert.Equal("DELETE, GET, HEAD, POST", rt.allow(false))
+
+	if h, ok := rt.handler(http.MethodHead); assert.True(ok) {
+		assert.Equal(testHandler(2), h.handler)
+	}
+	_, ok := rt.handler(http.MethodPut)
+	assert.False(ok)
+
+	// handler for any method
+	rt = rt.with("", &endpoint{handler: testHandler(4)})
+	if h, ok := rt.handler(http.MethodPut); assert.True(ok) {
+		assert.Equal(testHandler(4), h.handler)
+	}
+	assert.Equal("DELETE, GET, HEAD, POST", rt.allow(false))
+
+	// explicit HEAD
+	rt = rt.with(http.MethodHead, &endpoint{handler: testHandler(5)})
+	if h, ok := rt.handler(http.MethodHead); assert.True(ok) {
+		assert.Equal(testHandler(5), h.handler)
+	}
+	assert.Equal([]string{"DELETE", "GET", "HEAD", "POST"}, rt.methods())
+
+	assert.Equal("DELETE, GET, HEAD, OPTIONS, POST", rt.allow(true))
+
+	rt = rt.without("").without(http.MethodHead).without(http.MethodGet)
+	assert.Equal("DELETE, POST", rt.allow(false))
+
+	assert.Nil(rt.without(http.MethodPost).without(http.MethodDelete))
+}
+
+func TestRouter_HandleMethod(t *testing.T) {
+	assert := assert.New(t)
+	router := NewRouter()
+
+	router.Get("/users", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("list"))
+	})
+	router.Post("/users", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	router.Delete("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		method string
+		path   string
+		status int
+		allow  string
+	}{
+		{http.MethodGet, "/users", http.StatusOK, ""},
+		{http.MethodHead, "/users", http.StatusOK, ""},
+		{http.MethodPost, "/users", http.StatusCreated, ""},
+		{http.MethodPut, "/users", http.StatusMethodNotAllowed, "GET, HEAD, OPTIONS, POST"},
+		{http.MethodDelete, "/users/1", http.StatusNoContent, ""},
+		{http.MethodGet, "/users/1", http.StatusMethodNotAllowed, "DELETE, OPTIONS"},
+		{http.MethodGet, "/not-found", http.StatusNotFound, ""},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(test.method, test.path, nil)
+		router.ServeHTTP(w, r)
+
+		if !assert.Equal(test.status, w.Code, test.method+" "+test.path) {
+			return
+		}
+		assert.Equal(test.allow, w.Header().Get("Allow"))
+	}
+
+	// custom handler
+	router.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	assert.HTTPStatusCode(router.ServeHTTP, http.MethodPatch, "/users", nil, http.StatusTeapot)
+
+	// handler for any method
+	router.Handle("/users", testHandler(1))
+	assert.HTTPStatusCode(router.ServeHTTP, http.MethodPatch, "/users", nil, http.StatusNoContent)
+	assert.HTTPStatusCode(router.ServeHTTP, http.MethodPost, "/users", nil, http.StatusCreated)
+
+	var routes []string
+	for route := range router.Routes() {
+		routes = append(routes, route.Method+" "+route.Pattern)
+	}
+	assert.Equal(
+		[]string{" /users", "GET /users", "POST /users", "DELETE /users/{id}"},
+		routes,
+	)
+
+	router.RemoveMethod("", "/users")
+	router.RemoveMethod(http.MethodGet, "/users")
+	assert.HTTPStatusCode(router.ServeHTTP, http.MethodGet, "/users", nil, http.StatusTeapot)
+
+	router.RemoveMethod(http.MethodPost, "/users")
+	assert.HTTPStatusCode(router.ServeHTTP, http.MethodPost, "/users", nil, http.StatusNotFound)
+}
+
+func TestRouter_HandleMethod_params(t *testing.T) {
+	assert := assert.New(t)
+	router := NewRouter()
+
+	router.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.PathValue("id")))
+	})
+
+	// parameter names are shared by handlers of the route
+	assert.Panics(func() {
+		router.Post("/users/{uid}", func(w http.ResponseWriter, r *http.Request) {})
+	})
+	assert.Error(router.TryHandleMethod(http.MethodPut, "/users/{uid}", testHandler(1)))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	assert.Equal("1", w.Body.String())
+
+	// the only handler is replaced
+	router.Get("/users/{uid}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.PathValue("uid")))
+	})
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/2", nil))
+	assert.Equal("2", w.Body.String())
+
+	var routes []string
+	for route := range router.Routes() {
+		routes = append(routes, route.Method+" "+route.Pattern)
+	}
+	assert.Equal([]string{"GET /users/{uid}"}, routes)
+}
+
+func TestRouter_Options(t *testing.T) {
+	assert := assert.New(t)
+	router := NewRouter()
+
+	router.Get("/users", func(w http.ResponseWriter, r *http.Request) {})
+	router.Post("/users", func(w http.ResponseWriter, r *http.Request) {})
+	router.HandleMethod(http.MethodOptions, "/custom", testHandler(1))
+	router.Get("/custom", func(w http.ResponseWriter, r *http.Request) {})
+	router.Handle("/any", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	options := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodOptions, path, nil)
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	// automatic reply
+	w := options("/users")
+	assert.Equal(http.StatusNoContent, w.Code)
+	assert.Equal("GET, HEAD, OPTIONS, POST", w.Header().Get("Allow"))
+
+	// explicit handler
+	w = options("/custom")
+	assert.Equal(http.StatusNoContent, w.Code)
+	assert.Empty(w.Header().Get("Allow"))
+
+	// handler for any method
+	w = options("/any")
+	assert.Equal(http.StatusAccepted, w.Code)
+
+	// not found
+	w = options("/not-found")
+	assert.Equal(http.StatusNotFound, w.Code)
+
+	// CORS hook
+	cors := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			w.Header().Set("Access-Control-Allow-Methods", w.Header().Get("Allow"))
+			next.ServeHTTP(w, r)
+		})
+	}
+	router.OptionsHandler = cors(router.OptionsHandler)
+
+	w = options("/users")
+	assert.Equal(http.StatusNoContent, w.Code)
+	assert.Equal("*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal("GET, HEAD, OPTIONS, POST", w.Header().Get("Access-Control-Allow-Methods"))
+
+	// disabled
+	router.OptionsHandler = nil
+	w = options("/users")
+	assert.Equal(http.StatusMethodNotAllowed, w.Code)
+	assert.Equal("GET, HEAD, POST", w.Header().Get("Allow"))
+}
+
+func TestRouter_Meta(t *testing.T) {
+	assert := assert.New(t)
+	router := NewRouter()
+
+	// global middleware enforces route policy
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if scope, ok := MetaFrom(r)["scope"].(string); ok {
+				if r.Header.Get("X-Scope") != scope {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	var match RouteMatch
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		match, _ = MatchFrom(r)
+		w.WriteHeader(http.StatusNoContent)
+	}
+
+	router.Get("/public", handler)
+	router.Get("/admin/users", handler,
+		Meta{"scope": "admin", "team": "core"},
+		Meta{"doc": "List users"},
+	)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/public", nil)
+	router.ServeHTTP(w, r)
+	assert.Equal(http.StatusNoContent, w.Code)
+	assert.Nil(match.Meta)
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodGet, "/admin/users", nil)
+	router.ServeHTTP(w, r)
+	assert.Equal(http.StatusForbidden, w.Code)
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodGet, "/admin/users", nil)
+	r.Header.Set("X-Scope", "admin")
+	router.ServeHTTP(w, r)
+	assert.Equal(http.StatusNoContent, w.Code)
+	assert.Equal("/admin/users", match.Pattern)
+	assert.Equal(Meta{"scope": "admin", "team": "core", "doc": "List users"}, match.Meta)
+
+	// introspection
+	for route := range router.Routes() {
+		if route.Pattern == "/admin/users" {
+			assert.Equal("core", route.Meta["team"])
+		} else {
+			assert.Nil(route.Meta)
+		}
+	}
+}
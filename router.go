@@ -1,94 +1,625 @@
 package router
 
 import (
+	"context"
+	"iter"
 	"net/http"
+	"strings"
 	"sync"
+	"sync/atomic"
 )
 
 type MiddlewareFunc func(http.Handler) http.Handler
 
+// Route describes a registered route.
+type Route struct {
+	// Method is a request method.
+	// It is empty for handlers registered for any method.
+	Method  string
+	Pattern string
+	Handler http.Handler
+	Meta    Meta
+}
+
+// RouteMatch describes how the request path matched the registered pattern.
+type RouteMatch struct {
+	// Pattern is the registered pattern.
+	Pattern string
+	// Exact is true if the pattern is equal to the request path
+	// and false if the request path matched with the directory pattern.
+	Exact bool
+	// Tail is a part of the request path after the pattern.
+	// It is empty on exact match.
+	Tail string
+	// Params is a list of path parameters.
+	Params []PathParam
+	// Meta is a metadata of the matched route.
+	Meta Meta
+}
+
+type contextKey struct {
+	name string
+}
+
+func (k *contextKey) String() string {
+	return "router context " + k.name
+}
+
+// Key for request Context
+var matchContextKey = &contextKey{"match"}
+
+// MatchFrom returns the route matched for the request by Router.
+// Static route is defined by the request Pattern,
+// so pattern set by http.ServeMux is reported as a static route.
+func MatchFrom(r *http.Request) (RouteMatch, bool) {
+	if r.Pattern == "" {
+		return RouteMatch{}, false
+	}
+
+	m, ok := r.Context().Value(matchContextKey).(*RouteMatch)
+	if ok && m.Pattern == r.Pattern {
+		return *m, true
+	}
+
+	// static route without metadata is served without match in the context,
+	// match of the other router in the context is not related to the request
+	return RouteMatch{Pattern: r.Pattern, Exact: true}, true
+}
+
+// matchContext is a request context with the route match.
+type matchContext struct {
+	context.Context
+	match RouteMatch
+}
+
+func (c *matchContext) Value(key any) any {
+	if key == matchContextKey {
+		return &c.match
+	}
+
+	return c.Context.Value(key)
+}
+
+// matchCarrier is a request with the match context passed to the route handler.
+// Request copy and context are made with one allocation.
+// Carrier is not reused: derived contexts keep reference to the parent
+// after the handler returns, for example in the http.Transport.
+type matchCarrier struct {
+	ctx     matchContext
+	request http.Request
+}
+
+// MetaFrom returns metadata of the route matched for the request by Router.
+// Returns nil if route has no metadata. Metadata should not be modified.
+func MetaFrom(r *http.Request) Meta {
+	m, _ := MatchFrom(r)
+	return m.Meta
+}
+
+// Param returns value of the path parameter.
+// Path parameters are defined in the pattern as a whole segment: /streams/{id}
+func Param(r *http.Request, name string) string {
+	return r.PathValue(name)
+}
+
+// table is a snapshot of the router state.
+// Snapshot is never modified after publishing,
+// any change makes a new snapshot with path-copied radix tree.
+type table struct {
+	radix *Radix[*route]
+	mw    []MiddlewareFunc
+	// groups is a middleware for the group prefixes.
+	groups *Radix[[]MiddlewareFunc]
+	hosts  *hostTable
+	// names is a pattern for the route name.
+	names map[string]string
+	// events is a list of changes made to the table before publishing.
+	// It is nil if router has no observers.
+	events *[]Event
+}
+
+// compose returns a copy of the endpoint with handler wrapped
+// by router, group and route middleware.
+func (t *table) compose(pattern string, e *endpoint) *endpoint {
+	next := *e
+	handler := e.handler
+
+	wrap := func(mw []MiddlewareFunc) {
+		for i := len(mw) - 1; i >= 0; i-- {
+			handler = mw[i](handler)
+		}
+	}
+
+	wrap(e.mw)
+
+	var groups [][]MiddlewareFunc
+	t.groups.walkPattern(pattern, func(mw []MiddlewareFunc) {
+		groups = append(groups, mw)
+	})
+	for i := len(groups) - 1; i >= 0; i-- {
+		wrap(groups[i])
+	}
+
+	wrap(t.mw)
+
+	next.serve = handler
+	return &next
+}
+
+// recompose makes a new tree with all endpoints composed again.
+// It should be called on router or group middleware changes.
+func (t *table) recompose() {
+	radix := new(Radix[*route])
+
+	t.radix.Walk(func(pattern string, rt *route) bool {
+		next := rt.clone(func(e *endpoint) *endpoint {
+			return t.compose(pattern, e)
+		})
+
+		radix.InsertPattern(pattern, next)
+		return true
+	})
+
+	t.radix = radix
+}
+
 // Router is an HTTP request multiplexer.
 // It matches the URL of each incoming request against a list of registered
 // patterns and calls the handler for the pattern that most closely matches
 // the URL. There are two types of patterns:
 // 1. Exact match: /foo/bar
 // 2. Wildcard match: /foo/
+//
+// Requests are served without locks from the current snapshot
+// of the routing table. Changes are serialized and published atomically.
 type Router struct {
-	mutex sync.RWMutex
-	radix *Radix
-	mw    []MiddlewareFunc
+	mutex sync.Mutex
+	table atomic.Pointer[table]
 
 	NotFoundHandler http.Handler
+
+	// MethodNotAllowedHandler is called if path is matched,
+	// but there is no handler for the request method.
+	// Allow header is set before handler is called.
+	MethodNotAllowedHandler http.Handler
+
+	// OptionsHandler is called for OPTIONS request if path is matched,
+	// but there is no handler for OPTIONS method or for any method.
+	// Allow header is set before handler is called.
+	// Handler could be wrapped to add CORS headers to the automatic reply.
+	// If nil, OPTIONS request is handled as any other method.
+	OptionsHandler http.Handler
+
+	// NotAcceptableHandler is called if path and method are matched,
+	// but route variants are not acceptable by the Accept header.
+	NotAcceptableHandler http.Handler
+
+	// UnsupportedMediaTypeHandler is called if path and method are matched,
+	// but route variants are not matched by the Content-Type header.
+	UnsupportedMediaTypeHandler http.Handler
+
+	// Redirect policies are applied if the request path has no exact match,
+	// but an alternative form of the path has.
+	// Router replies with 301 for GET and HEAD requests and with 308
+	// for other methods, so the method and body are not changed.
+
+	// RedirectTrailingSlash redirects /foo/ to /foo and /foo to /foo/.
+	RedirectTrailingSlash bool
+	// RedirectFixedPath redirects to the cleaned path: /a//b/../c to /a/c.
+	// Cleaned path is redirected on a directory match too.
+	RedirectFixedPath bool
+	// RedirectCaseInsensitive redirects to the path registered with another case.
+	RedirectCaseInsensitive bool
+
+	// Strict makes Handle and other registration methods panic
+	// if the route conflicts with registered routes, same as TryHandle
+	// returns an error. By default the handler is replaced.
+	Strict bool
+
+	// Clock is a source of time for routes registered with HandleTTL
+	// and HandleUntil. If nil, system time is used.
+	Clock Clock
+
+	// expiry is a queue of route deadlines guarded by mutex.
+	expiry expiryQueue
+	timer  Timer
+
+	// observers and pending events are guarded by mutex.
+	observers   []*observer
+	pending     []Event
+	dispatching bool
 }
 
 // NewRouter returns a new router.
 func NewRouter() *Router {
-	return &Router{
-		radix:           new(Radix),
-		NotFoundHandler: http.NotFoundHandler(),
+	r := &Router{
+		NotFoundHandler:         http.NotFoundHandler(),
+		MethodNotAllowedHandler: MethodNotAllowedHandler(),
+		OptionsHandler:          OptionsHandler(),
+
+		NotAcceptableHandler:        NotAcceptableHandler(),
+		UnsupportedMediaTypeHandler: UnsupportedMediaTypeHandler(),
 	}
+	r.table.Store(&table{
+		radix:  new(Radix[*route]),
+		groups: new(Radix[[]MiddlewareFunc]),
+	})
+
+	return r
 }
 
-func (r *Router) Use(mw ...MiddlewareFunc) {
+// update makes a copy of the current snapshot, applies fn to it
+// and publishes the result.
+// Observers are notified after the mutex is released.
+func (r *Router) update(fn func(t *table)) {
+	dispatch := false
+	defer func() {
+		if dispatch {
+			r.dispatch()
+		}
+	}()
+
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	r.mw = append(r.mw, mw...)
+	t := *r.table.Load()
+	r.observe(&t)
+	fn(&t)
+	dispatch = r.publish(&t)
+}
+
+// Use appends middleware to the router.
+// Router middleware is called for all routes before group and route middleware.
+func (r *Router) Use(mw ...MiddlewareFunc) {
+	r.update(func(t *table) {
+		t.use(mw)
+	})
 }
 
 // Handle registers the handler for the given pattern.
+// Handler serves any request method that has no own handler.
 // If a handler already exists for pattern, Handle replaces it.
-func (r *Router) Handle(pattern string, handler http.Handler) {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
-
-	r.radix.Insert(pattern, handler)
+// Pattern may have path parameters: /streams/{id}/sessions.
+// Options could be a route middleware, Meta, a route Name,
+// or conditions: Header, Accept, ContentType.
+//
+// Pattern may have query conditions: /api/stream?action=start&verbose
+// Handler is called if the request has query parameter action
+// with value start and parameter verbose with any value.
+// Handlers with conditions have priority over the handler without
+// conditions, handler with more conditions is checked first.
+// Panics if pattern is not valid.
+func (r *Router) Handle(pattern string, handler http.Handler, opts ...Option) {
+	r.HandleMethod("", pattern, handler, opts...)
 }
 
 // HandleFunc registers the handler function for the given pattern.
 // If a handler already exists for pattern, HandleFunc replaces it.
-func (r *Router) HandleFunc(pattern string, handler http.HandlerFunc) {
-	r.Handle(pattern, handler)
+func (r *Router) HandleFunc(pattern string, handler http.HandlerFunc, opts ...Option) {
+	r.Handle(pattern, handler, opts...)
 }
 
-// Lookup returns the handler for the given path.
-// If no handler is found, it returns the NotFound handler.
-func (r *Router) Lookup(path string) http.Handler {
-	r.mutex.RLock()
-	defer r.mutex.RUnlock()
+// HandleMethod registers the handler for the given method and pattern.
+// If method is empty, handler serves any method same as Handle.
+// If a handler already exists for method and pattern, HandleMethod replaces it.
+// Handlers of the same pattern share parameter names,
+// so it panics if pattern has names different from other handlers.
+func (r *Router) HandleMethod(method, pattern string, handler http.Handler, opts ...Option) {
+	if handler == nil {
+		r.RemoveMethod(method, pattern)
+		return
+	}
+
+	if err := r.handle(method, pattern, handler, opts, r.Strict); err != nil {
+		panic(err)
+	}
+}
+
+// TryHandle registers the handler for the given pattern same as Handle.
+// Returns ConflictError if handler already exists for pattern,
+// or if the route shadows routes of the mounted router.
+func (r *Router) TryHandle(pattern string, handler http.Handler, opts ...Option) error {
+	return r.TryHandleMethod("", pattern, handler, opts...)
+}
+
+// TryHandleMethod registers the handler for the given method and pattern
+// same as HandleMethod. Returns ConflictError same as TryHandle.
+// Panics if pattern is not valid.
+func (r *Router) TryHandleMethod(method, pattern string, handler http.Handler, opts ...Option) error {
+	return r.handle(method, pattern, handler, opts, true)
+}
+
+// handle registers the endpoint.
+// If strict is true, conflict with registered routes is returned
+// and router is not changed.
+func (r *Router) handle(method, pattern string, handler http.Handler, opts []Option, strict bool) error {
+	path, e := newRouteEndpoint(pattern, handler, opts)
+
+	var err error
 
-	if handler, ok := r.radix.LookupPath(path).(http.Handler); ok {
-		return handler
+	r.update(func(t *table) {
+		err = t.handle(method, path, e, strict)
+	})
+
+	return err
+}
+
+// Get registers the handler function for GET requests.
+// HEAD requests are served by the same handler.
+func (r *Router) Get(pattern string, handler http.HandlerFunc, opts ...Option) {
+	r.HandleMethod(http.MethodGet, pattern, handler, opts...)
+}
+
+// Post registers the handler function for POST requests.
+func (r *Router) Post(pattern string, handler http.HandlerFunc, opts ...Option) {
+	r.HandleMethod(http.MethodPost, pattern, handler, opts...)
+}
+
+// Put registers the handler function for PUT requests.
+func (r *Router) Put(pattern string, handler http.HandlerFunc, opts ...Option) {
+	r.HandleMethod(http.MethodPut, pattern, handler, opts...)
+}
+
+// Delete registers the handler function for DELETE requests.
+func (r *Router) Delete(pattern string, handler http.HandlerFunc, opts ...Option) {
+	r.HandleMethod(http.MethodDelete, pattern, handler, opts...)
+}
+
+// Patch registers the handler function for PATCH requests.
+func (r *Router) Patch(pattern string, handler http.HandlerFunc, opts ...Option) {
+	r.HandleMethod(http.MethodPatch, pattern, handler, opts...)
+}
+
+// Lookup returns the handler for GET request with the given path.
+// If no handler is found, it returns nil.
+func (r *Router) Lookup(path string) http.Handler {
+	if rt := r.table.Load().radix.LookupPath(path); rt != nil {
+		if e, ok := rt.handler(http.MethodGet); ok {
+			return e.handler
+		}
 	}
 
 	return nil
 }
 
-// Remove removes the handler for the given path.
-func (r *Router) Remove(path string) {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
+// Remove removes all handlers for the given pattern.
+// If pattern has query conditions, only handlers with the same
+// conditions are removed.
+func (r *Router) Remove(pattern string) {
+	r.update(func(t *table) {
+		t.remove(pattern)
+	})
+}
 
-	r.radix.Remove(path)
+// RemoveMethod removes the handler for the given method and pattern.
+// Empty method removes handler registered for any method.
+func (r *Router) RemoveMethod(method, pattern string) {
+	r.update(func(t *table) {
+		t.removeMethod(method, pattern)
+	})
 }
 
-func (r *Router) prepare(path string) http.Handler {
-	r.mutex.RLock()
-	defer r.mutex.RUnlock()
+// newRouteEndpoint makes endpoint for the pattern.
+// Returns pattern path without query conditions.
+// Panics if pattern is not valid.
+func newRouteEndpoint(pattern string, handler http.Handler, opts []Option) (string, *endpoint) {
+	path, query := splitQuery(pattern)
+	mustParsePattern(path)
+
+	e := newEndpoint(handler, opts)
+	e.source = caller()
+	e.queryMatch, e.query = mustParseQuery(pattern, query)
+	e.prepareConditions()
+
+	return path, e
+}
+
+// use appends middleware to the router.
+func (t *table) use(mw []MiddlewareFunc) {
+	// full slice expression to not share array with previous snapshot
+	t.mw = append(t.mw[:len(t.mw):len(t.mw)], mw...)
+	t.recompose()
+	t.record(Event{Kind: EventUse})
+}
+
+// handle registers the endpoint for the pattern path.
+// If strict is true, conflict with registered routes is returned
+// and table is not changed.
+// ConflictError is returned if other handlers of the route have different
+// parameter names, they are shared by all handlers of the route.
+func (t *table) handle(method, path string, e *endpoint, strict bool) error {
+	old, _ := t.radix.LookupPattern(path)
+
+	if err := t.paramsConflict(old, method, path, e); err != nil {
+		return err
+	}
+
+	rt := old.with(method, t.compose(path, e))
+	radix, _, _ := t.radix.insertPattern(path, rt, true)
+
+	if strict {
+		if err := t.conflict(radix, method, path, e); err != nil {
+			return err
+		}
+	}
+
+	t.changed(path, old, rt)
+	t.radix = radix
+	t.rename(path, rt)
+
+	return nil
+}
+
+// remove removes handlers for the pattern.
+func (t *table) remove(pattern string) {
+	path, query := splitQuery(pattern)
+	if query == "" {
+		t.set(path, nil)
+		return
+	}
+
+	_, query = mustParseQuery(pattern, query)
+
+	rt, ok := t.radix.LookupPattern(path)
+	if !ok {
+		return
+	}
 
-	if handler, ok := r.radix.LookupPath(path).(http.Handler); ok {
-		for i := len(r.mw) - 1; i >= 0; i-- {
-			handler = r.mw[i](handler)
+	for method := range rt.variants {
+		rt = rt.withoutVariant(method, query)
+		if rt == nil {
+			break
 		}
+	}
+	t.set(path, rt)
+}
+
+// removeMethod removes handler for the method and pattern.
+func (t *table) removeMethod(method, pattern string) {
+	path, query := splitQuery(pattern)
+	_, query = mustParseQuery(pattern, query)
+
+	rt, ok := t.radix.LookupPattern(path)
+	if !ok {
+		return
+	}
+
+	t.set(path, rt.withoutVariant(method, query))
+}
+
+// set updates the route for the pattern.
+// If route is nil, pattern is removed.
+func (t *table) set(pattern string, rt *route) {
+	old, _ := t.radix.LookupPattern(pattern)
+	t.changed(pattern, old, rt)
+
+	if rt != nil {
+		t.radix, _, _ = t.radix.insertPattern(pattern, rt, true)
+	} else {
+		t.radix, _, _ = t.radix.removePattern(pattern, true)
+	}
+	t.rename(pattern, rt)
+}
+
+// Routes returns an iterator over registered routes.
+// Routes are ordered by pattern and method.
+// Routes of the mounted routers are listed with full path.
+func (r *Router) Routes() iter.Seq[Route] {
+	return r.table.Load().routes()
+}
 
-		return handler
+// routes returns an iterator over routes of the table.
+func (t *table) routes() iter.Seq[Route] {
+	return func(yield func(Route) bool) {
+		t.walk("", func(method, pattern string, e *endpoint) bool {
+			return yield(Route{Method: method, Pattern: pattern, Handler: e.handler, Meta: e.meta})
+		})
 	}
+}
+
+// walk calls fn for each registered endpoint.
+// Endpoints of the mounted routers are listed with full path.
+// Prefix is prepended to the patterns.
+func (t *table) walk(prefix string, fn func(method, pattern string, e *endpoint) bool) bool {
+	next := true
+
+	t.radix.Walk(func(pattern string, rt *route) bool {
+		pattern = prefix + pattern
+
+		next = rt.each(func(method string, e *endpoint) bool {
+			if m, ok := e.handler.(*mount); ok {
+				return m.router.table.Load().walk(strings.TrimSuffix(pattern, "/"), fn)
+			}
+
+			if e.query != "" {
+				return fn(method, pattern+"?"+e.query, e)
+			}
+
+			return fn(method, pattern, e)
+		})
+
+		return next
+	})
 
-	return r.NotFoundHandler
+	return next
 }
 
 // ServeHTTP dispatches the request to the handler whose pattern most closely
 // matches the request URL.
+// Matched route is available in the handler with MatchFrom.
+// Handler of the static route without metadata receives the request
+// with the Pattern field set. Other handlers receive a copy of the request
+// with the match in the context.
 func (r *Router) ServeHTTP(response http.ResponseWriter, request *http.Request) {
-	r.prepare(request.URL.Path).ServeHTTP(response, request)
+	t := r.table.Load()
+
+	if t.hosts != nil {
+		if router := t.hosts.lookup(request.Host); router != nil {
+			router.ServeHTTP(response, request)
+			return
+		}
+	}
+
+	m, ok := t.radix.MatchPath(request.URL.Path)
+	if !(ok && m.Exact) &&
+		(r.RedirectTrailingSlash || r.RedirectFixedPath || r.RedirectCaseInsensitive) &&
+		r.redirect(t, response, request, m.Key) {
+		return
+	}
+
+	if !ok {
+		r.NotFoundHandler.ServeHTTP(response, request)
+		return
+	}
+
+	e, status := m.Value.match(request)
+	switch status {
+	case http.StatusNotFound:
+		r.NotFoundHandler.ServeHTTP(response, request)
+		return
+	case http.StatusNotAcceptable:
+		r.NotAcceptableHandler.ServeHTTP(response, request)
+		return
+	case http.StatusUnsupportedMediaType:
+		r.UnsupportedMediaTypeHandler.ServeHTTP(response, request)
+		return
+	}
+
+	if e == nil {
+		response.Header().Set("Allow", m.Value.allow(r.OptionsHandler != nil))
+		if request.Method == http.MethodOptions && r.OptionsHandler != nil {
+			r.OptionsHandler.ServeHTTP(response, request)
+		} else {
+			r.MethodNotAllowedHandler.ServeHTTP(response, request)
+		}
+		return
+	}
+
+	if m.Exact && m.Params == nil && e.meta == nil {
+		// match of the static route is defined by the pattern,
+		// request is served without allocations same as http.ServeMux
+		request.Pattern = m.Key
+		e.serve.ServeHTTP(response, request)
+		return
+	}
+
+	c := new(matchCarrier)
+	c.ctx.Context = request.Context()
+	c.ctx.match = RouteMatch{
+		Pattern: m.Key,
+		Exact:   m.Exact,
+		Tail:    m.Tail,
+		Params:  m.Params,
+		Meta:    e.meta,
+	}
+	// copy of the request made by WithContext is not escaped,
+	// so the request is copied to the carrier without allocation
+	c.request = *request.WithContext(&c.ctx)
+	c.request.Pattern = m.Key
+
+	for _, p := range m.Params {
+		c.request.SetPathValue(p.Key, p.Value)
+	}
+
+	e.serve.ServeHTTP(response, &c.request)
 }
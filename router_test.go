@@ -3,6 +3,9 @@ package router
 import (
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -180,3 +183,296 @@ func TestRouter_Use(t *testing.T) {
 	assert.Equal("1", response.Header.Get("X-Middleware-1"))
 	assert.Equal("2", response.Header.Get("X-Middleware-2"))
 }
+
+func TestRouter_Use_compose(t *testing.T) {
+	assert := assert.New(t)
+	router := NewRouter()
+
+	calls := 0
+	counter := func(next http.Handler) http.Handler {
+		calls++
+		return next
+	}
+
+	router.Handle("/a", testHandler(1), MiddlewareFunc(counter))
+	assert.Equal(1, calls)
+
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/a", nil)
+		router.ServeHTTP(w, r)
+		assert.Equal(http.StatusNoContent, w.Code)
+	}
+	assert.Equal(1, calls)
+
+	// middleware added after Handle applies to registered routes
+	router.Use(traceMW("global"))
+	assert.Equal(2, calls)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/a", nil)
+	router.ServeHTTP(w, r)
+	assert.Equal("global", w.Header().Get("X-Trace"))
+
+	router.Remove("/a")
+	router.Use(traceMW("other"))
+	assert.Equal(2, calls)
+}
+
+// discardWriter is a response writer without allocations.
+type discardWriter struct {
+	header http.Header
+}
+
+func (w *discardWriter) Header() http.Header         { return w.header }
+func (w *discardWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *discardWriter) WriteHeader(int)             {}
+
+func newBenchRouter() *Router {
+	router := NewRouter()
+	router.Use(func(next http.Handler) http.Handler { return next })
+	router.Group("/api", func(g *Group) {
+		g.Use(func(next http.Handler) http.Handler { return next })
+		g.Get("/users", func(w http.ResponseWriter, r *http.Request) {},
+			MiddlewareFunc(func(next http.Handler) http.Handler { return next }))
+		g.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {})
+	})
+
+	return router
+}
+
+func TestRouter_ServeHTTP_allocs(t *testing.T) {
+	router := newBenchRouter()
+	w := &discardWriter{header: make(http.Header)}
+	r := httptest.NewRequest(http.MethodGet, "/api/users", nil)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		router.ServeHTTP(w, r)
+	})
+	assert.Zero(t, allocs)
+}
+
+func BenchmarkRouter_ServeHTTP(b *testing.B) {
+	router := newBenchRouter()
+	w := &discardWriter{header: make(http.Header)}
+
+	b.Run("static", func(b *testing.B) {
+		r := httptest.NewRequest(http.MethodGet, "/api/users", nil)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			router.ServeHTTP(w, r)
+		}
+	})
+
+	b.Run("param", func(b *testing.B) {
+		r := httptest.NewRequest(http.MethodGet, "/api/users/1", nil)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			router.ServeHTTP(w, r)
+		}
+	})
+}
+
+func TestRouter_MatchFrom(t *testing.T) {
+	assert := assert.New(t)
+	router := NewRouter()
+
+	var match RouteMatch
+	var pattern string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m, ok := MatchFrom(r)
+		assert.True(ok)
+		match = m
+		pattern = r.Pattern
+	})
+
+	router.Handle("/static/", handler)
+	router.Handle("/api/users", handler)
+
+	tests := []struct {
+		path string
+		want RouteMatch
+	}{
+		{"/static/", RouteMatch{Pattern: "/static/", Exact: true}},
+		{"/static/css/main.css", RouteMatch{Pattern: "/static/", Tail: "css/main.css"}},
+		{"/api/users", RouteMatch{Pattern: "/api/users", Exact: true}},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, test.path, nil)
+		router.ServeHTTP(w, r)
+
+		if !assert.Equal(test.want, match, test.path) {
+			return
+		}
+		assert.Equal(test.want.Pattern, pattern)
+	}
+
+	// match does not depend on the request path
+	router.Use(func(next http.Handler) http.Handler {
+		return http.StripPrefix("/static", next)
+	})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/static/css/main.css", nil))
+	assert.Equal(RouteMatch{Pattern: "/static/", Tail: "css/main.css"}, match)
+
+	// not found
+	r := httptest.NewRequest(http.MethodGet, "/api/users/", nil)
+	_, ok := MatchFrom(r)
+	assert.False(ok)
+}
+
+func TestRouter_Param(t *testing.T) {
+	assert := assert.New(t)
+	router := NewRouter()
+
+	var id, session string
+	var params []PathParam
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id = Param(r, "id")
+		session = Param(r, "session")
+		m, _ := MatchFrom(r)
+		params = m.Params
+	})
+
+	router.Handle("/streams/{id}/sessions/{session}", handler)
+	router.Handle("/streams/new/sessions/7", testHandler(1))
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/streams/42/sessions/7", nil)
+	router.ServeHTTP(w, r)
+
+	assert.Equal("42", id)
+	assert.Equal("7", session)
+	assert.Equal([]PathParam{{"id", "42"}, {"session", "7"}}, params)
+
+	// static route has priority
+	assert.HTTPStatusCode(
+		router.ServeHTTP,
+		http.MethodGet,
+		"/streams/new/sessions/7",
+		nil,
+		http.StatusNoContent,
+	)
+
+	assert.Panics(func() {
+		router.Handle("/streams/{id", handler)
+	})
+
+	router.Remove("/streams/{x}/sessions/{y}")
+	assert.Nil(router.Lookup("/streams/42/sessions/7"))
+}
+
+func TestRouter_CatchAll(t *testing.T) {
+	assert := assert.New(t)
+	router := NewRouter()
+
+	var file string
+	router.HandleFunc("/static/{file...}", func(w http.ResponseWriter, r *http.Request) {
+		file = Param(r, "file")
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/static/css/main.css", nil)
+	router.ServeHTTP(w, r)
+	assert.Equal("css/main.css", file)
+
+	var patterns []string
+	for route := range router.Routes() {
+		patterns = append(patterns, route.Pattern)
+	}
+	assert.Equal([]string{"/static/{file...}"}, patterns)
+
+	// directory pattern and catch-all are the same route
+	router.Remove("/static/")
+	assert.Nil(router.Lookup("/static/css/main.css"))
+}
+
+func TestRouter_Routes(t *testing.T) {
+	assert := assert.New(t)
+	router := NewRouter()
+
+	router.Handle("/api/users/", testHandler(3))
+	router.Handle("/", testHandler(1))
+	router.Handle("/api/users", testHandler(2))
+
+	var routes []Route
+	for route := range router.Routes() {
+		routes = append(routes, route)
+	}
+
+	assert.Equal(
+		[]Route{
+			{Pattern: "/", Handler: testHandler(1)},
+			{Pattern: "/api/users", Handler: testHandler(2)},
+			{Pattern: "/api/users/", Handler: testHandler(3)},
+		},
+		routes,
+	)
+}
+
+func TestRouter_concurrency(t *testing.T) {
+	assert := assert.New(t)
+	router := NewRouter()
+
+	router.Handle("/stable", testHandler(1))
+
+	var writers, readers sync.WaitGroup
+	done := make(chan struct{})
+
+	// writers
+	for i := 0; i < 4; i++ {
+		writers.Add(1)
+		go func(i int) {
+			defer writers.Done()
+
+			prefix := "/w" + strconv.Itoa(i) + "/"
+			for j := 0; j < 500; j++ {
+				pattern := prefix + strconv.Itoa(j%50)
+				router.Handle(pattern, testHandler(j))
+				if j%3 == 0 {
+					router.Remove(pattern)
+				}
+				if j%100 == 0 {
+					router.Use(func(next http.Handler) http.Handler {
+						return next
+					})
+				}
+			}
+		}(i)
+	}
+
+	// readers
+	var failed atomic.Int64
+	for i := 0; i < 8; i++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest(http.MethodGet, "/stable", nil)
+				router.ServeHTTP(w, r)
+				if w.Code != http.StatusNoContent {
+					failed.Add(1)
+				}
+
+				_ = router.Lookup("/w1/10")
+			}
+		}()
+	}
+
+	writers.Wait()
+	close(done)
+	readers.Wait()
+
+	assert.Zero(failed.Load())
+	assert.Equal(testHandler(1), router.Lookup("/stable"))
+}
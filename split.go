@@ -0,0 +1,280 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// SplitTarget is a target of the Split handler.
+type SplitTarget struct {
+	// Name identifies the target, it is available in handler with SplitFrom.
+	Name    string
+	Handler http.Handler
+	// Weight is a share of requests relative to the sum of weights.
+	Weight int
+}
+
+// splitBuckets is a number of buckets for the sticky keys.
+// Buckets are assigned to targets by weights, on weight change
+// only buckets over the new share of the target are moved
+// to targets under their share. Other keys keep their targets.
+const splitBuckets = 10000
+
+// splitState is a snapshot of the targets.
+type splitState struct {
+	targets []SplitTarget
+	total   int
+	// owners is an index of the target for each bucket,
+	// or -1 if bucket is not assigned because all weights are zero.
+	owners []int32
+}
+
+// quotas returns number of buckets for each target by weights.
+// Rounding remainder is given to targets with the largest fraction.
+func (s *splitState) quotas() []int {
+	quotas := make([]int, len(s.targets))
+	if s.total == 0 {
+		return quotas
+	}
+
+	rest := splitBuckets
+	for i, t := range s.targets {
+		quotas[i] = t.Weight * splitBuckets / s.total
+		rest -= quotas[i]
+	}
+
+	order := make([]int, len(s.targets))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return s.targets[order[i]].Weight*splitBuckets%s.total >
+			s.targets[order[j]].Weight*splitBuckets%s.total
+	})
+	for _, i := range order[:rest] {
+		quotas[i]++
+	}
+
+	return quotas
+}
+
+// prepare assigns buckets to targets by weights.
+// Owners of the previous state are kept if the target share allows.
+func (s *splitState) prepare(prev []int32) {
+	s.total = 0
+	for _, t := range s.targets {
+		s.total += t.Weight
+	}
+
+	s.owners = make([]int32, splitBuckets)
+	if prev != nil {
+		copy(s.owners, prev)
+	} else {
+		for i := range s.owners {
+			s.owners[i] = -1
+		}
+	}
+
+	quotas := s.quotas()
+
+	// release buckets over the target share from the end
+	counts := make([]int, len(s.targets))
+	for _, o := range s.owners {
+		if o >= 0 {
+			counts[o]++
+		}
+	}
+	for i := len(s.owners) - 1; i >= 0; i-- {
+		if o := s.owners[i]; o >= 0 && counts[o] > quotas[o] {
+			counts[o]--
+			s.owners[i] = -1
+		}
+	}
+
+	// assign free buckets to targets under their share in order
+	t := 0
+	for i, o := range s.owners {
+		if o >= 0 {
+			continue
+		}
+		for t < len(quotas) && counts[t] >= quotas[t] {
+			t++
+		}
+		if t == len(quotas) {
+			break
+		}
+		s.owners[i] = int32(t)
+		counts[t]++
+	}
+}
+
+// Split is a handler that distributes requests between weighted targets,
+// for example to send 5% of traffic to a new handler:
+//
+//	split := router.NewSplit(
+//	    router.SplitTarget{Name: "stable", Handler: stable, Weight: 95},
+//	    router.SplitTarget{Name: "canary", Handler: canary, Weight: 5},
+//	)
+//	split.Cookie = "canary"
+//	r.Handle("/api/transcode", split)
+//
+// Weights could be changed at runtime with SetWeight.
+type Split struct {
+	// Cookie is a name of the cookie for sticky assignment.
+	// If request has no cookie, it is set with a random value.
+	Cookie string
+	// Header is a name of the request header for sticky assignment,
+	// for example client or user identifier.
+	// Header is used if Cookie is not defined.
+	Header string
+
+	mutex sync.Mutex
+	state atomic.Pointer[splitState]
+}
+
+// NewSplit returns a new Split handler.
+// Panics if target has no name or handler, name is duplicated,
+// or weight is negative.
+func NewSplit(targets ...SplitTarget) *Split {
+	state := &splitState{}
+
+	for _, t := range targets {
+		if t.Name == "" || t.Handler == nil {
+			panic("router: split target should have name and handler")
+		}
+
+		for _, v := range state.targets {
+			if v.Name == t.Name {
+				panic(fmt.Sprintf("router: duplicate split target %q", t.Name))
+			}
+		}
+
+		if t.Weight < 0 {
+			panic(fmt.Sprintf("router: negative weight for split target %q", t.Name))
+		}
+
+		state.targets = append(state.targets, t)
+	}
+	state.prepare(nil)
+
+	s := &Split{}
+	s.state.Store(state)
+
+	return s
+}
+
+// SetWeight changes weight of the target.
+// Sticky requests keep their targets, except requests in the buckets
+// over the new share of the target, they are moved to targets
+// under their share.
+// Returns false if target is not found.
+// Panics if weight is negative.
+func (s *Split) SetWeight(name string, weight int) bool {
+	if weight < 0 {
+		panic(fmt.Sprintf("router: negative weight for split target %q", name))
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	prev := s.state.Load()
+	next := &splitState{
+		targets: make([]SplitTarget, len(prev.targets)),
+	}
+	copy(next.targets, prev.targets)
+
+	found := false
+	for i := range next.targets {
+		if next.targets[i].Name == name {
+			next.targets[i].Weight = weight
+			found = true
+		}
+	}
+
+	if !found {
+		return false
+	}
+
+	next.prepare(prev.owners)
+	s.state.Store(next)
+
+	return true
+}
+
+// Weights returns current weights of the targets by name.
+func (s *Split) Weights() map[string]int {
+	state := s.state.Load()
+
+	weights := make(map[string]int, len(state.targets))
+	for _, t := range state.targets {
+		weights[t.Name] = t.Weight
+	}
+
+	return weights
+}
+
+// Key for request Context
+var splitContextKey = &contextKey{"split"}
+
+// SplitFrom returns name of the target selected by Split for the request.
+func SplitFrom(r *http.Request) (string, bool) {
+	name, ok := r.Context().Value(splitContextKey).(string)
+	return name, ok
+}
+
+// hashKey returns a hash of the sticky key.
+func hashKey(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return h.Sum64()
+}
+
+// bucket returns a bucket for the request.
+// Request with the same sticky key has the same bucket.
+func (s *Split) bucket(w http.ResponseWriter, r *http.Request) int {
+	if s.Cookie != "" {
+		key := ""
+		if c, err := r.Cookie(s.Cookie); err == nil && c.Value != "" {
+			key = c.Value
+		} else {
+			key = strconv.FormatUint(rand.Uint64(), 36)
+			http.SetCookie(w, &http.Cookie{
+				Name:     s.Cookie,
+				Value:    key,
+				Path:     "/",
+				HttpOnly: true,
+			})
+		}
+
+		return int(hashKey(key) % splitBuckets)
+	}
+
+	if s.Header != "" {
+		if key := r.Header.Get(s.Header); key != "" {
+			return int(hashKey(key) % splitBuckets)
+		}
+	}
+
+	return rand.IntN(splitBuckets)
+}
+
+// ServeHTTP passes request to the target selected by weights.
+// Replies with 503 if all weights are zero.
+func (s *Split) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	state := s.state.Load()
+	if state.total == 0 {
+		http.Error(w, "503 service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	t := state.targets[state.owners[s.bucket(w, r)]]
+	ctx := context.WithValue(r.Context(), splitContextKey, t.Name)
+	t.Handler.ServeHTTP(w, r.WithContext(ctx))
+}
@@ -0,0 +1,228 @@
+package router
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newSplitTarget(name string, weight int) SplitTarget {
+	return SplitTarget{
+		Name: name,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			variant, _ := SplitFrom(r)
+			io.WriteString(w, variant)
+		}),
+		Weight: weight,
+	}
+}
+
+func TestSplit(t *testing.T) {
+	assert := assert.New(t)
+
+	split := NewSplit(
+		newSplitTarget("stable", 90),
+		newSplitTarget("canary", 10),
+	)
+
+	router := NewRouter()
+	router.Handle("/api/transcode", split)
+
+	counts := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/transcode", nil))
+		counts[w.Body.String()]++
+	}
+
+	assert.Equal(1000, counts["stable"]+counts["canary"])
+	assert.InDelta(100, counts["canary"], 60)
+
+	// runtime weight changes
+	assert.True(split.SetWeight("canary", 0))
+	assert.False(split.SetWeight("unknown", 10))
+	assert.Equal(map[string]int{"stable": 90, "canary": 0}, split.Weights())
+
+	for i := 0; i < 100; i++ {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/transcode", nil))
+		assert.Equal("stable", w.Body.String())
+	}
+
+	split.SetWeight("stable", 0)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/transcode", nil))
+	assert.Equal(http.StatusServiceUnavailable, w.Code)
+}
+
+func TestSplit_Header(t *testing.T) {
+	assert := assert.New(t)
+
+	split := NewSplit(
+		newSplitTarget("a", 50),
+		newSplitTarget("b", 50),
+	)
+	split.Header = "X-Client-Id"
+
+	for i := 0; i < 20; i++ {
+		id := strconv.Itoa(i)
+
+		var first string
+		for j := 0; j < 10; j++ {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("X-Client-Id", id)
+			split.ServeHTTP(w, r)
+
+			if j == 0 {
+				first = w.Body.String()
+			}
+			assert.Equal(first, w.Body.String())
+		}
+	}
+}
+
+func TestSplit_SetWeight_sticky(t *testing.T) {
+	assert := assert.New(t)
+
+	split := NewSplit(
+		newSplitTarget("stable", 95),
+		newSplitTarget("canary", 5),
+	)
+	split.Header = "X-Client-Id"
+
+	assign := func() []string {
+		list := make([]string, 1000)
+		for i := range list {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("X-Client-Id", strconv.Itoa(i))
+			split.ServeHTTP(w, r)
+			list[i] = w.Body.String()
+		}
+		return list
+	}
+
+	before := assign()
+	split.SetWeight("stable", 90)
+	split.SetWeight("canary", 10)
+	after := assign()
+
+	// only the marginal clients are moved to canary
+	moved := 0
+	for i := range before {
+		if before[i] == after[i] {
+			continue
+		}
+		moved++
+		assert.Equal("stable", before[i])
+	}
+	assert.InDelta(50, moved, 30)
+}
+
+func TestSplit_SetWeight_three(t *testing.T) {
+	assert := assert.New(t)
+
+	split := NewSplit(
+		newSplitTarget("a", 1),
+		newSplitTarget("b", 1),
+		newSplitTarget("c", 1),
+	)
+	split.Header = "X-Client-Id"
+
+	assign := func() []string {
+		list := make([]string, 3000)
+		for i := range list {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("X-Client-Id", strconv.Itoa(i))
+			split.ServeHTTP(w, r)
+			list[i] = w.Body.String()
+		}
+		return list
+	}
+
+	before := assign()
+
+	// clients of a are moved to b and c, others are not changed
+	split.SetWeight("a", 0)
+	after := assign()
+	counts := make(map[string]int)
+	for i := range before {
+		if before[i] != "a" {
+			assert.Equal(before[i], after[i])
+		}
+		counts[after[i]]++
+	}
+	assert.Zero(counts["a"])
+	assert.InDelta(1500, counts["b"], 150)
+
+	// clients are moved only to a
+	split.SetWeight("a", 1)
+	restored := assign()
+	moved := 0
+	for i := range after {
+		if restored[i] != after[i] {
+			assert.Equal("a", restored[i])
+			moved++
+		}
+	}
+	assert.InDelta(1000, moved, 150)
+
+	// clients are moved only to c
+	split.SetWeight("c", 2)
+	changed := assign()
+	for i := range restored {
+		if changed[i] != restored[i] {
+			assert.Equal("c", changed[i])
+		}
+	}
+}
+
+func TestSplit_Cookie(t *testing.T) {
+	assert := assert.New(t)
+
+	split := NewSplit(
+		newSplitTarget("a", 50),
+		newSplitTarget("b", 50),
+	)
+	split.Cookie = "variant"
+
+	w := httptest.NewRecorder()
+	split.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	first := w.Body.String()
+
+	cookies := w.Result().Cookies()
+	if !assert.Len(cookies, 1) {
+		return
+	}
+	assert.Equal("variant", cookies[0].Name)
+
+	for i := 0; i < 10; i++ {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.AddCookie(cookies[0])
+		split.ServeHTTP(w, r)
+
+		assert.Equal(first, w.Body.String())
+		assert.Empty(w.Result().Cookies())
+	}
+}
+
+func TestNewSplit_panics(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Panics(func() {
+		NewSplit(newSplitTarget("a", 1), newSplitTarget("a", 1))
+	})
+	assert.Panics(func() {
+		NewSplit(newSplitTarget("a", -1))
+	})
+	assert.Panics(func() {
+		NewSplit(SplitTarget{Name: "a"})
+	})
+}
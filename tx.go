@@ -0,0 +1,146 @@
+package router
+
+import (
+	"iter"
+	"net/http"
+)
+
+// Tx is a set of router changes published in one atomic step.
+// Tx is valid only inside the Update function.
+// Router is locked while the function runs, so changes should be made
+// with Tx methods: Router and Group methods called inside the function
+// wait for the lock forever.
+type Tx struct {
+	router *Router
+	table  *table
+}
+
+// Update applies changes made by fn to a copy of the routing table
+// and publishes the result in one atomic step.
+// Requests are served by the previous table until fn returns,
+// so requests never see a half-updated table.
+// If fn returns an error or panics, router is not changed.
+// Router is locked until fn returns, so fn should not call methods
+// of the router or its groups, it deadlocks. Use Tx methods instead.
+func (r *Router) Update(fn func(tx *Tx) error) error {
+	dispatch := false
+	defer func() {
+		if dispatch {
+			r.dispatch()
+		}
+	}()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	t := *r.table.Load()
+	r.observe(&t)
+	tx := &Tx{
+		router: r,
+		table:  &t,
+	}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	dispatch = r.publish(&t)
+	return nil
+}
+
+// Use appends middleware to the router same as Router.Use.
+func (tx *Tx) Use(mw ...MiddlewareFunc) {
+	tx.table.use(mw)
+}
+
+// UseGroup appends middleware to the group with the prefix same as Group.Use.
+func (tx *Tx) UseGroup(prefix string, mw ...MiddlewareFunc) {
+	tx.table.groupUse(prefix, mw)
+}
+
+// Handle registers the handler for the given pattern same as Router.Handle.
+func (tx *Tx) Handle(pattern string, handler http.Handler, opts ...Option) {
+	tx.HandleMethod("", pattern, handler, opts...)
+}
+
+// HandleFunc registers the handler function for the given pattern.
+func (tx *Tx) HandleFunc(pattern string, handler http.HandlerFunc, opts ...Option) {
+	tx.Handle(pattern, handler, opts...)
+}
+
+// HandleMethod registers the handler for the given method and pattern
+// same as Router.HandleMethod.
+// In the strict mode it panics on conflict, use TryHandleMethod
+// to return an error from the Update function.
+func (tx *Tx) HandleMethod(method, pattern string, handler http.Handler, opts ...Option) {
+	if handler == nil {
+		tx.RemoveMethod(method, pattern)
+		return
+	}
+
+	if err := tx.handle(method, pattern, handler, opts, tx.router.Strict); err != nil {
+		panic(err)
+	}
+}
+
+// TryHandle registers the handler for the given pattern same as Router.TryHandle.
+func (tx *Tx) TryHandle(pattern string, handler http.Handler, opts ...Option) error {
+	return tx.TryHandleMethod("", pattern, handler, opts...)
+}
+
+// TryHandleMethod registers the handler for the given method and pattern
+// same as Router.TryHandleMethod.
+func (tx *Tx) TryHandleMethod(method, pattern string, handler http.Handler, opts ...Option) error {
+	return tx.handle(method, pattern, handler, opts, true)
+}
+
+func (tx *Tx) handle(method, pattern string, handler http.Handler, opts []Option, strict bool) error {
+	path, e := newRouteEndpoint(pattern, handler, opts)
+	return tx.table.handle(method, path, e, strict)
+}
+
+// Get registers the handler function for GET requests.
+func (tx *Tx) Get(pattern string, handler http.HandlerFunc, opts ...Option) {
+	tx.HandleMethod(http.MethodGet, pattern, handler, opts...)
+}
+
+// Post registers the handler function for POST requests.
+func (tx *Tx) Post(pattern string, handler http.HandlerFunc, opts ...Option) {
+	tx.HandleMethod(http.MethodPost, pattern, handler, opts...)
+}
+
+// Put registers the handler function for PUT requests.
+func (tx *Tx) Put(pattern string, handler http.HandlerFunc, opts ...Option) {
+	tx.HandleMethod(http.MethodPut, pattern, handler, opts...)
+}
+
+// Delete registers the handler function for DELETE requests.
+func (tx *Tx) Delete(pattern string, handler http.HandlerFunc, opts ...Option) {
+	tx.HandleMethod(http.MethodDelete, pattern, handler, opts...)
+}
+
+// Patch registers the handler function for PATCH requests.
+func (tx *Tx) Patch(pattern string, handler http.HandlerFunc, opts ...Option) {
+	tx.HandleMethod(http.MethodPatch, pattern, handler, opts...)
+}
+
+// Mount attaches the router to the prefix same as Router.Mount.
+func (tx *Tx) Mount(prefix string, router *Router) {
+	tx.Handle(prefix, newMount(prefix, router))
+}
+
+// Remove removes all handlers for the given pattern same as Router.Remove.
+func (tx *Tx) Remove(pattern string) {
+	tx.table.remove(pattern)
+}
+
+// RemoveMethod removes the handler for the given method and pattern.
+func (tx *Tx) RemoveMethod(method, pattern string) {
+	tx.table.removeMethod(method, pattern)
+}
+
+// Routes returns an iterator over routes with changes made in the transaction.
+func (tx *Tx) Routes() iter.Seq[Route] {
+	t := *tx.table
+	return t.routes()
+}
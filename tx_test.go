@@ -0,0 +1,159 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouter_Update(t *testing.T) {
+	assert := assert.New(t)
+	router := NewRouter()
+
+	router.Handle("/old", testHandler(1))
+
+	err := router.Update(func(tx *Tx) error {
+		tx.Remove("/old")
+		tx.Handle("/a", testHandler(2))
+		tx.Get("/b", func(w http.ResponseWriter, r *http.Request) {})
+		tx.Mount("/api/", NewRouter())
+
+		// changes are not published until fn returns
+		assert.Equal(testHandler(1), router.Lookup("/old"))
+		assert.Nil(router.Lookup("/a"))
+
+		count := 0
+		for range tx.Routes() {
+			count++
+		}
+		// mounted router has no routes
+		assert.Equal(2, count)
+
+		return nil
+	})
+	assert.NoError(err)
+	assert.Nil(router.Lookup("/old"))
+	assert.Equal(testHandler(2), router.Lookup("/a"))
+	assert.NotNil(router.Lookup("/b"))
+}
+
+func TestRouter_Update_group(t *testing.T) {
+	assert := assert.New(t)
+	router := NewRouter()
+
+	router.Get("/admin/users", func(w http.ResponseWriter, r *http.Request) {})
+	router.Get("/public", func(w http.ResponseWriter, r *http.Request) {})
+
+	assert.NoError(router.Update(func(tx *Tx) error {
+		tx.UseGroup("/admin", traceMW("admin"))
+		return nil
+	}))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/users", nil))
+	assert.Equal([]string{"admin"}, w.Header().Values("X-Trace"))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/public", nil))
+	assert.Empty(w.Header().Values("X-Trace"))
+}
+
+func TestRouter_Update_rollback(t *testing.T) {
+	assert := assert.New(t)
+	router := NewRouter()
+
+	router.Handle("/a", testHandler(1))
+
+	errTest := errors.New("test")
+	err := router.Update(func(tx *Tx) error {
+		tx.Remove("/a")
+		tx.Handle("/b", testHandler(2))
+		tx.Use(traceMW("tx"))
+		return errTest
+	})
+	assert.ErrorIs(err, errTest)
+	assert.Equal(testHandler(1), router.Lookup("/a"))
+	assert.Nil(router.Lookup("/b"))
+
+	// conflict returned by TryHandle
+	err = router.Update(func(tx *Tx) error {
+		tx.Handle("/b", testHandler(2))
+		return tx.TryHandle("/a", testHandler(3))
+	})
+	var conflict *ConflictError
+	assert.True(errors.As(err, &conflict))
+	assert.Nil(router.Lookup("/b"))
+
+	// panic
+	assert.Panics(func() {
+		router.Update(func(tx *Tx) error {
+			tx.Handle("/b", testHandler(2))
+			tx.Handle("/{", testHandler(3))
+			return nil
+		})
+	})
+	assert.Nil(router.Lookup("/b"))
+
+	// mutex is released
+	router.Handle("/c", testHandler(4))
+	assert.Equal(testHandler(4), router.Lookup("/c"))
+}
+
+func TestRouter_Update_atomic(t *testing.T) {
+	assert := assert.New(t)
+	router := NewRouter()
+
+	const routes = 200
+
+	var wg sync.WaitGroup
+	var partial atomic.Int64
+	done := make(chan struct{})
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				// routes are listed from one snapshot
+				count := 0
+				for range router.Routes() {
+					count++
+				}
+				if count != 0 && count != routes {
+					partial.Add(1)
+				}
+			}
+		}()
+	}
+
+	for j := 0; j < 20; j++ {
+		router.Update(func(tx *Tx) error {
+			for i := 0; i < routes; i++ {
+				if j%2 == 0 {
+					tx.Handle("/r/"+strconv.Itoa(i), testHandler(i))
+				} else {
+					tx.Remove("/r/" + strconv.Itoa(i))
+				}
+			}
+			return nil
+		})
+	}
+
+	close(done)
+	wg.Wait()
+
+	assert.Zero(partial.Load())
+}
@@ -0,0 +1,162 @@
+package router
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+type nameOption string
+
+func (n nameOption) apply(e *endpoint) {
+	e.name = string(n)
+}
+
+// Name returns option to set the route name.
+// Named route could be used to build URL with Router.URL.
+// If name is used for several routes, the last registered route is used.
+func Name(name string) Option {
+	return nameOption(name)
+}
+
+// rename updates names of the route endpoints for the pattern.
+// Route could be nil if pattern is removed.
+func (t *table) rename(pattern string, rt *route) {
+	belongs := func(v string) bool {
+		path, _ := splitQuery(v)
+		return path == pattern
+	}
+
+	changed := false
+	for _, v := range t.names {
+		if belongs(v) {
+			changed = true
+			break
+		}
+	}
+	if rt != nil && !changed {
+		rt.each(func(method string, e *endpoint) bool {
+			changed = e.name != ""
+			return !changed
+		})
+	}
+	if !changed {
+		return
+	}
+
+	names := make(map[string]string, len(t.names)+1)
+	for k, v := range t.names {
+		if !belongs(v) {
+			names[k] = v
+		}
+	}
+	if rt != nil {
+		rt.each(func(method string, e *endpoint) bool {
+			if e.name == "" {
+				return true
+			}
+
+			if e.query != "" {
+				names[e.name] = pattern + "?" + e.query
+			} else {
+				names[e.name] = pattern
+			}
+			return true
+		})
+	}
+
+	t.names = names
+}
+
+// URL returns the path for the route with the given name.
+// Params is a list of key-value pairs: "id", "42".
+// Path parameters of the route pattern are replaced with values,
+// other pairs are added to the query.
+// Query conditions of the route are added to the query.
+// Returns error if route not found or path parameter is missing.
+func (r *Router) URL(name string, params ...string) (string, error) {
+	pattern, ok := r.table.Load().names[name]
+	if !ok {
+		return "", fmt.Errorf("router: route %q not found", name)
+	}
+
+	pattern, conditions := splitQuery(pattern)
+	matchers, _, _ := parseQuery(conditions)
+
+	if len(params)%2 != 0 {
+		return "", fmt.Errorf("router: route %q: odd number of params", name)
+	}
+
+	p := mustParsePattern(pattern)
+	if p == nil && len(params) == 0 && len(matchers) == 0 {
+		return pattern, nil
+	}
+
+	var query url.Values
+	values := make(map[string]string, len(params)/2)
+
+	for i := 0; i < len(params); i += 2 {
+		key, value := params[i], params[i+1]
+		if p != nil && slices.Contains(p.params, key) {
+			values[key] = value
+			continue
+		}
+
+		if query == nil {
+			query = make(url.Values)
+		}
+		query.Add(key, value)
+	}
+
+	for _, m := range matchers {
+		if query == nil {
+			query = make(url.Values)
+		}
+
+		if !m.presence {
+			query.Set(m.key, m.value)
+		} else if !query.Has(m.key) {
+			query.Set(m.key, "")
+		}
+	}
+
+	var b strings.Builder
+
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '{' {
+			b.WriteByte(pattern[i])
+			continue
+		}
+
+		l := strings.IndexByte(pattern[i:], '}')
+		key := strings.TrimSuffix(pattern[i+1:i+l], "...")
+		value, ok := values[key]
+		if !ok {
+			return "", fmt.Errorf("router: route %q: missing parameter %q", name, key)
+		}
+
+		if p.catchAll && i+l+1 == len(pattern) {
+			// catch-all value keeps path separators
+			segments := strings.Split(value, "/")
+			for j, s := range segments {
+				segments[j] = url.PathEscape(s)
+			}
+			b.WriteString(strings.Join(segments, "/"))
+		} else {
+			if value == "" {
+				return "", fmt.Errorf("router: route %q: empty parameter %q", name, key)
+			}
+			b.WriteString(url.PathEscape(value))
+		}
+
+		i += l
+	}
+
+	if query != nil {
+		b.WriteByte('?')
+		b.WriteString(query.Encode())
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,68 @@
+package router
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouter_URL(t *testing.T) {
+	assert := assert.New(t)
+	router := NewRouter()
+
+	h := func(w http.ResponseWriter, r *http.Request) {}
+	router.Get("/", h, Name("index"))
+	router.Get("/api/users/{id}", h, Name("user.show"))
+	router.Post("/api/users/{id}", h, Name("user.update"))
+	router.Get("/streams/{id}/sessions/{sid}", h, Name("session"))
+	router.Get("/static/{file...}", h, Name("static"))
+
+	tests := []struct {
+		name   string
+		params []string
+		want   string
+	}{
+		{"index", nil, "/"},
+		{"index", []string{"page", "2", "q", "a b"}, "/?page=2&q=a+b"},
+		{"user.show", []string{"id", "42"}, "/api/users/42"},
+		{"user.update", []string{"id", "a/b"}, "/api/users/a%2Fb"},
+		{"user.show", []string{"id", "42", "tab", "info"}, "/api/users/42?tab=info"},
+		{"session", []string{"sid", "2", "id", "1"}, "/streams/1/sessions/2"},
+		{"static", []string{"file", "css/main file.css"}, "/static/css/main%20file.css"},
+		{"static", []string{"file", ""}, "/static/"},
+	}
+
+	for _, test := range tests {
+		url, err := router.URL(test.name, test.params...)
+		if assert.NoError(err, test.name) {
+			assert.Equal(test.want, url, test.name)
+		}
+	}
+
+	// errors
+	_, err := router.URL("unknown")
+	assert.Error(err)
+	_, err = router.URL("user.show")
+	assert.EqualError(err, `router: route "user.show": missing parameter "id"`)
+	_, err = router.URL("user.show", "id", "")
+	assert.Error(err)
+	_, err = router.URL("user.show", "id")
+	assert.Error(err)
+
+	// name is removed with the route
+	router.RemoveMethod(http.MethodGet, "/api/users/{id}")
+	_, err = router.URL("user.show", "id", "42")
+	assert.Error(err)
+	url, _ := router.URL("user.update", "id", "42")
+	assert.Equal("/api/users/42", url)
+
+	router.Remove("/api/users/{id}")
+	_, err = router.URL("user.update", "id", "42")
+	assert.Error(err)
+
+	// name is moved to the new pattern
+	router.Get("/v2/", h, Name("index"))
+	url, _ = router.URL("index")
+	assert.Equal("/v2/", url)
+}